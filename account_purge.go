@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+const accountPurgeInterval = time.Hour
+
+// runAccountPurge deletes accounts whose deletion grace period is over,
+// checking once per interval until ctx is done.
+func (cfg *apiConfig) runAccountPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := cfg.purgeDeletedAccounts(ctx)
+		if err != nil {
+			log.Printf("Error purging deleted accounts: %s", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted accounts", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeDeletedAccounts removes due accounts. Everything a user owns, from
+// chirps and refresh tokens to likes, follows and drafts, goes with the
+// user row through ON DELETE CASCADE, and the email becomes free to sign
+// up with again.
+func (cfg *apiConfig) purgeDeletedAccounts(ctx context.Context) (int64, error) {
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Locking the rows first means a user cancelling at the last moment
+	// either wins and keeps their files, or waits and is purged.
+	userIDs, err := qtx.LockUsersDueForDeletion(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	attachments, err := qtx.GetAttachmentsForUsers(ctx, userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := qtx.DeleteUsers(ctx, userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range attachments {
+		cfg.deleteAttachmentBlobs(ctx, attachment)
+	}
+
+	return purged, nil
+}
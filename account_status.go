@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// requirePostingAllowed checks that userID may publish content, writing the
+// error response itself when they may not.
+func (cfg *apiConfig) requirePostingAllowed(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "User not found", err)
+		return false
+	}
+
+	if user.SuspendedUntil.Valid && user.SuspendedUntil.Time.After(time.Now().UTC()) {
+		msg := fmt.Sprintf("Account is suspended until %s", user.SuspendedUntil.Time.Format(time.RFC3339))
+		respondWithError(w, http.StatusForbidden, msg, nil)
+		return false
+	}
+
+	if user.DeleteAfter.Valid {
+		respondWithError(w, http.StatusForbidden, "Account is scheduled for deletion", nil)
+		return false
+	}
+
+	if cfg.unverifiedPolicy == unverifiedReadOnly && !user.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Verify your email address before posting", nil)
+		return false
+	}
+
+	return true
+}
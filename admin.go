@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/auth"
+)
+
+// requireAdmin authenticates the request and checks that the caller is an
+// admin, writing the error response itself when they aren't.
+func (cfg *apiConfig) requireAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return uuid.Nil, false
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return uuid.Nil, false
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "User not found", err)
+		return uuid.Nil, false
+	}
+
+	if !user.IsAdmin {
+		respondWithError(w, http.StatusForbidden, "Admin access required", nil)
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/chirptext"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// saveChirpEntities replaces the hashtags and mentions indexed for a chirp
+// with the ones in its current body. q should be bound to the transaction
+// that created or edited the chirp so the index never disagrees with the
+// body.
+func saveChirpEntities(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	err := q.DeleteChirpHashtags(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range chirptext.ExtractHashtags(chirp.Body) {
+		hashtag, err := q.UpsertHashtag(ctx, tag)
+		if err != nil {
+			return err
+		}
+		err = q.AddChirpHashtag(ctx, database.AddChirpHashtagParams{
+			ChirpID:   chirp.ID,
+			HashtagID: hashtag.ID,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return saveChirpMentions(ctx, q, chirp)
+}
+
+// saveChirpMentions resolves @handles against existing users. Handles that
+// don't belong to anyone are left as plain text.
+func saveChirpMentions(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	err := q.DeleteChirpMentions(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	mentions := chirptext.ExtractMentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	handles := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		handles = append(handles, strings.ToLower(mention.Handle))
+	}
+	users, err := q.GetUsersByHandles(ctx, handles)
+	if err != nil {
+		return err
+	}
+	userIDByHandle := map[string]uuid.UUID{}
+	for _, user := range users {
+		userIDByHandle[strings.ToLower(user.Handle.String)] = user.ID
+	}
+
+	for _, mention := range mentions {
+		userID, ok := userIDByHandle[strings.ToLower(mention.Handle)]
+		if !ok {
+			continue
+		}
+		err = q.AddChirpMention(ctx, database.AddChirpMentionParams{
+			ChirpID:     chirp.ID,
+			UserID:      userID,
+			StartOffset: int32(mention.Start),
+			EndOffset:   int32(mention.End),
+			CreatedAt:   chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+const (
+	chirpPurgeInterval = time.Hour
+
+	// unattachedAttachmentRetention is how long an upload may wait to be
+	// attached to a chirp or used as an avatar before it is removed.
+	unattachedAttachmentRetention = 24 * time.Hour
+)
+
+// runChirpPurge hard-deletes chirps that have been in the trash for longer
+// than the retention window, along with uploads that were never used,
+// checking once per interval until ctx is done.
+func (cfg *apiConfig) runChirpPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := cfg.purgeDeletedChirps(ctx)
+		if err != nil {
+			log.Printf("Error purging deleted chirps: %s", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted chirps", purged)
+		}
+
+		removed, err := cfg.purgeUnattachedAttachments(ctx)
+		if err != nil {
+			log.Printf("Error purging unattached attachments: %s", err)
+		} else if removed > 0 {
+			log.Printf("Purged %d unattached attachments", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cfg *apiConfig) purgeDeletedChirps(ctx context.Context) (int64, error) {
+	cutoff := sql.NullTime{Time: time.Now().UTC().Add(-cfg.chirpRetention), Valid: true}
+
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Attachment rows go with their chirp through the foreign key, so the
+	// files have to be collected first.
+	attachments, err := qtx.GetPurgeableAttachments(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := qtx.PurgeDeletedChirps(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range attachments {
+		cfg.deleteAttachmentBlobs(ctx, attachment)
+	}
+
+	return purged, nil
+}
+
+func (cfg *apiConfig) purgeUnattachedAttachments(ctx context.Context) (int, error) {
+	attachments, err := cfg.db.DeleteUnattachedAttachments(ctx, int32(unattachedAttachmentRetention/time.Second))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range attachments {
+		cfg.deleteAttachmentBlobs(ctx, attachment)
+	}
+
+	return len(attachments), nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+func databaseChirpToChirp(chirp database.Chirp) Chirp {
+	converted := Chirp{
+		ID:               chirp.ID,
+		CreatedAt:        chirp.CreatedAt,
+		UpdatedAt:        chirp.UpdatedAt,
+		Body:             chirp.Body,
+		UserID:           chirp.UserID,
+		ModerationStatus: chirp.ModerationStatus,
+		Hashtags:         []string{},
+		Mentions:         []Mention{},
+		Attachments:      []Attachment{},
+	}
+	if chirp.PublishAt.Valid {
+		publishAt := chirp.PublishAt.Time
+		converted.PublishAt = &publishAt
+	}
+	if chirp.InReplyTo.Valid {
+		inReplyTo := chirp.InReplyTo.UUID
+		converted.InReplyTo = &inReplyTo
+	}
+	return converted
+}
+
+// chirpsToResponse converts database chirps into API chirps, fills in the
+// counters that are stored in other tables and embeds the original of every
+// rechirp and quote. Each counter is one batched query rather than one per
+// chirp. viewerID personalises the response and may be uuid.Nil for
+// anonymous requests.
+func (cfg *apiConfig) chirpsToResponse(ctx context.Context, viewerID uuid.UUID, chirps []database.Chirp) ([]Chirp, error) {
+	response, err := cfg.chirpsWithCounts(ctx, viewerID, chirps)
+	if err != nil {
+		return nil, err
+	}
+
+	originalIDs := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.RechirpOf.Valid {
+			originalIDs = append(originalIDs, chirp.RechirpOf.UUID)
+		}
+		if chirp.QuoteOf.Valid {
+			originalIDs = append(originalIDs, chirp.QuoteOf.UUID)
+		}
+	}
+	if len(originalIDs) == 0 {
+		return response, nil
+	}
+
+	// Originals are embedded one level deep only, so a quote of a quote
+	// shows the chirp it quotes but not what that one quoted.
+	originals, err := cfg.db.GetChirpsByIDs(ctx, originalIDs)
+	if err != nil {
+		return nil, err
+	}
+	convertedOriginals, err := cfg.chirpsWithCounts(ctx, viewerID, originals)
+	if err != nil {
+		return nil, err
+	}
+	originalByID := map[uuid.UUID]Chirp{}
+	for i, original := range originals {
+		if chirpVisibleTo(original, viewerID) {
+			originalByID[original.ID] = convertedOriginals[i]
+		}
+	}
+
+	for i, chirp := range chirps {
+		if original, ok := originalByID[chirp.RechirpOf.UUID]; chirp.RechirpOf.Valid && ok {
+			response[i].RechirpOf = &original
+		}
+		if original, ok := originalByID[chirp.QuoteOf.UUID]; chirp.QuoteOf.Valid && ok {
+			response[i].QuotedChirp = &original
+		}
+	}
+	return response, nil
+}
+
+func (cfg *apiConfig) chirpsWithCounts(ctx context.Context, viewerID uuid.UUID, chirps []database.Chirp) ([]Chirp, error) {
+	response := make([]Chirp, 0, len(chirps))
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		response = append(response, databaseChirpToChirp(chirp))
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+	if len(chirpIDs) == 0 {
+		return response, nil
+	}
+
+	replyCounts, err := cfg.db.GetReplyCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	replyCountByID := map[uuid.UUID]int64{}
+	for _, row := range replyCounts {
+		replyCountByID[row.ChirpID] = row.ReplyCount
+	}
+
+	likeCounts, err := cfg.db.GetLikeCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	likeCountByID := map[uuid.UUID]int64{}
+	for _, row := range likeCounts {
+		likeCountByID[row.ChirpID] = row.LikeCount
+	}
+
+	rechirpCounts, err := cfg.db.GetRechirpCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	rechirpCountByID := map[uuid.UUID]int64{}
+	for _, row := range rechirpCounts {
+		rechirpCountByID[row.ChirpID] = row.RechirpCount
+	}
+
+	hashtags, err := cfg.db.GetHashtagsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	hashtagsByID := map[uuid.UUID][]string{}
+	for _, row := range hashtags {
+		hashtagsByID[row.ChirpID] = append(hashtagsByID[row.ChirpID], row.Tag)
+	}
+
+	mentions, err := cfg.db.GetMentionsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	mentionsByID := map[uuid.UUID][]Mention{}
+	for _, row := range mentions {
+		mentionsByID[row.ChirpID] = append(mentionsByID[row.ChirpID], Mention{
+			UserID: row.UserID,
+			Handle: row.Handle.String,
+			Start:  row.StartOffset,
+			End:    row.EndOffset,
+		})
+	}
+
+	attachments, err := cfg.db.GetAttachmentsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	attachmentsByID := map[uuid.UUID][]Attachment{}
+	for _, attachment := range attachments {
+		attachmentsByID[attachment.ChirpID.UUID] = append(attachmentsByID[attachment.ChirpID.UUID], cfg.databaseAttachmentToAttachment(attachment))
+	}
+
+	pollsByID, err := cfg.pollsForChirps(ctx, viewerID, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	likedByViewer := map[uuid.UUID]bool{}
+	if viewerID != uuid.Nil {
+		liked, err := cfg.db.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{
+			UserID:   viewerID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, chirpID := range liked {
+			likedByViewer[chirpID] = true
+		}
+	}
+
+	bookmarkedByViewer := map[uuid.UUID]bool{}
+	if viewerID != uuid.Nil {
+		bookmarked, err := cfg.db.GetBookmarkedChirpIDs(ctx, database.GetBookmarkedChirpIDsParams{
+			UserID:   viewerID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, chirpID := range bookmarked {
+			bookmarkedByViewer[chirpID] = true
+		}
+	}
+
+	for i := range response {
+		response[i].ReplyCount = replyCountByID[response[i].ID]
+		response[i].LikeCount = likeCountByID[response[i].ID]
+		response[i].RechirpCount = rechirpCountByID[response[i].ID]
+		response[i].LikedByMe = likedByViewer[response[i].ID]
+		response[i].Bookmarked = bookmarkedByViewer[response[i].ID]
+		response[i].Poll = pollsByID[response[i].ID]
+		if tags, ok := hashtagsByID[response[i].ID]; ok {
+			response[i].Hashtags = tags
+		}
+		if chirpMentions, ok := mentionsByID[response[i].ID]; ok {
+			response[i].Mentions = chirpMentions
+		}
+		if chirpAttachments, ok := attachmentsByID[response[i].ID]; ok {
+			response[i].Attachments = chirpAttachments
+		}
+	}
+	return response, nil
+}
+
+func (cfg *apiConfig) chirpToResponse(ctx context.Context, viewerID uuid.UUID, chirp database.Chirp) (Chirp, error) {
+	response, err := cfg.chirpsToResponse(ctx, viewerID, []database.Chirp{chirp})
+	if err != nil {
+		return Chirp{}, err
+	}
+	return response[0], nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+const (
+	chirpSchedulerInterval = 10 * time.Second
+	chirpSchedulerBatch    = 100
+)
+
+// runChirpScheduler publishes scheduled chirps once they are due, checking
+// once per interval until ctx is done. The queue lives in the database, so
+// chirps that fell due while the server was down go out on the first run
+// after it starts, and several servers can run the scheduler side by side.
+func (cfg *apiConfig) runChirpScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			published, err := cfg.publishDueChirps(ctx)
+			if err != nil {
+				log.Printf("Error publishing scheduled chirps: %s", err)
+				break
+			}
+			if published < chirpSchedulerBatch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDueChirps publishes one batch of due chirps. Hashtags and mentions
+// are saved again so they carry the publish time and pick up handles that
+// were registered since the chirp was written.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) (int, error) {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirps, err := qtx.PublishDueChirps(ctx, database.PublishDueChirpsParams{
+		PublishAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		Limit:     chirpSchedulerBatch,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, chirp := range chirps {
+		err = saveChirpEntities(ctx, qtx, chirp)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return len(chirps), nil
+}
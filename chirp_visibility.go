@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// Values of chirps.moderation_status. Held chirps are waiting for a
+// moderator and hidden chirps were taken down by one; only their author can
+// see either.
+const (
+	chirpStatusVisible = "visible"
+	chirpStatusHeld    = "held"
+	chirpStatusHidden  = "hidden"
+)
+
+// chirpPublic reports whether anyone may see a chirp: it isn't waiting for
+// or removed by moderation, and isn't scheduled for later.
+func chirpPublic(chirp database.Chirp) bool {
+	return chirp.ModerationStatus == chirpStatusVisible && !chirp.PublishAt.Valid
+}
+
+// chirpVisibleTo is the single-chirp counterpart of the visibility filter
+// in the listing queries. Authors can always see their own chirps.
+func chirpVisibleTo(chirp database.Chirp, viewerID uuid.UUID) bool {
+	return chirpPublic(chirp) || chirp.UserID == viewerID
+}
+
+// nullViewerID turns a viewer from viewerID into a query parameter that
+// matches no author when the request is anonymous.
+func nullViewerID(viewerID uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: viewerID, Valid: viewerID != uuid.Nil}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+	chirpymail "github.com/seantesterman/chirpy/internal/mail"
+)
+
+const emailVerificationTTL = 48 * time.Hour
+
+// unverifiedPolicy decides what accounts that haven't verified their email
+// address may do. It is set with UNVERIFIED_ACCOUNTS and defaults to
+// unverifiedAllow, so read-only accounts are opt-in.
+type unverifiedPolicy string
+
+const (
+	// unverifiedAllow lets unverified accounts do everything.
+	unverifiedAllow unverifiedPolicy = "allow"
+	// unverifiedReadOnly lets unverified accounts read, follow and like
+	// but not publish anything.
+	unverifiedReadOnly unverifiedPolicy = "read_only"
+)
+
+func parseUnverifiedPolicy(raw string) (unverifiedPolicy, error) {
+	switch policy := unverifiedPolicy(raw); policy {
+	case unverifiedAllow, unverifiedReadOnly:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown policy %q", raw)
+}
+
+// parseEmail checks that email is a bare address such as
+// "sean@example.com", without a display name, and lowercases it so an
+// address is stored the same way however it was typed.
+func parseEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", errors.New("Invalid email address")
+	}
+	return strings.ToLower(email), nil
+}
+
+// verificationPayload is what a verification token is signed over, so a
+// token only verifies the address it was sent to.
+func verificationPayload(email string) string {
+	return "verify-email:" + email
+}
+
+// sendVerificationEmail issues a new verification token for the user's
+// current address and mails it to them.
+func (cfg *apiConfig) sendVerificationEmail(ctx context.Context, user database.User) error {
+	verification, err := cfg.db.CreateEmailVerification(ctx, database.CreateEmailVerificationParams{
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().UTC().Add(emailVerificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	token := auth.MakeSignedToken(verification.ID, verificationPayload(verification.Email), cfg.secret)
+	return cfg.mailer.Send(ctx, chirpymail.Message{
+		To:      verification.Email,
+		Subject: "Verify your Chirpy email address",
+		Body: fmt.Sprintf(
+			"Use this code to verify your email address:\n\n%s\n\nIt expires in %d hours. If you didn't sign up for Chirpy you can ignore this email.\n",
+			token, int(emailVerificationTTL.Hours()),
+		),
+	})
+}
+
+// sendVerificationEmailInBackground is for handlers where the account
+// change has already been saved. The email is sent after the response, the
+// same way password reset emails are, so a slow mail server can't hold up
+// the request. A failed send is only logged; the user can ask for another
+// email.
+func (cfg *apiConfig) sendVerificationEmailInBackground(ctx context.Context, user database.User) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailSendTimeout)
+	go func() {
+		defer cancel()
+		err := cfg.sendVerificationEmail(ctx, user)
+		if err != nil {
+			log.Printf("Error sending verification email to user %s: %s", user.ID, err)
+		}
+	}()
+}
+
+func (cfg *apiConfig) handlerUsersVerify(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Token string `json:"token"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	verificationID, err := auth.ParseSignedToken(params.Token)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid verification token", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	verification, err := qtx.GetEmailVerification(r.Context(), verificationID)
+	if err == nil {
+		err = auth.CheckSignedToken(params.Token, verificationPayload(verification.Email), cfg.secret)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid verification token", err)
+		return
+	}
+
+	if !time.Now().UTC().Before(verification.ExpiresAt) {
+		respondWithError(w, http.StatusBadRequest, "Verification token has expired", nil)
+		return
+	}
+
+	// Marking the token used and checking the result makes it single-use
+	// even when the same token is submitted twice at once.
+	_, err = qtx.UseEmailVerification(r.Context(), verification.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "Verification token has already been used", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+
+	verified, err := qtx.SetEmailVerified(r.Context(), database.SetEmailVerifiedParams{
+		ID:    verification.UserID,
+		Email: verification.Email,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+	if verified == 0 {
+		respondWithError(w, http.StatusBadRequest, "Email address has changed since the token was sent", nil)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUsersVerifyResend(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "User not found", err)
+		return
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusConflict, "Email is already verified", nil)
+		return
+	}
+
+	err = cfg.sendVerificationEmail(r.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send verification email", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,13 +3,12 @@ module github.com/seantesterman/chirpy
 go 1.23.1
 
 require (
+	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
+	github.com/rivo/uniseg v0.4.7
 	golang.org/x/crypto v0.28.0
-)
-
-require (
-	github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
+	golang.org/x/text v0.19.0
 )
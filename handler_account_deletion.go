@@ -0,0 +1,116 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// accountDeletionGrace is how long a user has to change their mind before
+// their account and everything they own is purged.
+const accountDeletionGrace = 14 * 24 * time.Hour
+
+// handlerUsersMeDelete schedules the caller's account for deletion. The
+// account keeps working during the grace period apart from posting. Its
+// refresh tokens are revoked, so other devices are signed out once their
+// access tokens expire. Logging in again, or POST
+// /api/users/me/cancel-deletion, cancels the deletion.
+func (cfg *apiConfig) handlerUsersMeDelete(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		CurrentPassword string `json:"current_password"`
+	}
+	type response struct {
+		DeleteAfter time.Time `json:"delete_after"`
+	}
+
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	if !cfg.checkCurrentPassword(w, r, userID, params.CurrentPassword) {
+		return
+	}
+
+	deleteAfter := time.Now().UTC().Add(accountDeletionGrace)
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't schedule account deletion", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	scheduled, err := qtx.ScheduleUserDeletion(r.Context(), database.ScheduleUserDeletionParams{
+		ID:          userID,
+		DeleteAfter: sql.NullTime{Time: deleteAfter, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't schedule account deletion", err)
+		return
+	}
+	if scheduled == 0 {
+		respondWithError(w, http.StatusConflict, "Account is already scheduled for deletion", nil)
+		return
+	}
+
+	err = qtx.RevokeAllRefreshTokens(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't schedule account deletion", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't schedule account deletion", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, response{DeleteAfter: deleteAfter})
+}
+
+func (cfg *apiConfig) handlerUsersMeCancelDeletion(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	cancelled, err := cfg.db.CancelUserDeletion(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't cancel account deletion", err)
+		return
+	}
+	if cancelled == 0 {
+		respondWithError(w, http.StatusConflict, "Account is not scheduled for deletion", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
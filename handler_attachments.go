@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/media"
+)
+
+const maxChirpAttachments = 4
+
+type Attachment struct {
+	ID           uuid.UUID `json:"id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+}
+
+func (cfg *apiConfig) handlerAttachmentsCreate(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	// Leave headroom over the image limit for the multipart framing.
+	r.Body = http.MaxBytesReader(w, r.Body, media.MaxUploadSize+(1<<20))
+	err = r.ParseMultipartForm(media.MaxUploadSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse upload", err)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Missing file", err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, media.MaxUploadSize+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't read upload", err)
+		return
+	}
+
+	img, err := media.ProcessImage(data)
+	if errors.Is(err, media.ErrTooLarge) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, err.Error(), err)
+		return
+	}
+	if errors.Is(err, media.ErrUnsupportedType) {
+		respondWithError(w, http.StatusUnsupportedMediaType, err.Error(), err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't process image", err)
+		return
+	}
+
+	attachmentID := uuid.New()
+	blobKey := attachmentID.String() + img.Extension
+	thumbnailKey := attachmentID.String() + "-thumb" + img.ThumbnailExtension
+
+	err = cfg.blobStore.Put(r.Context(), blobKey, bytes.NewReader(img.Data))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store image", err)
+		return
+	}
+	err = cfg.blobStore.Put(r.Context(), thumbnailKey, bytes.NewReader(img.Thumbnail))
+	if err != nil {
+		cfg.blobStore.Delete(r.Context(), blobKey)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store image", err)
+		return
+	}
+
+	attachment, err := cfg.db.CreateAttachment(r.Context(), database.CreateAttachmentParams{
+		ID:           attachmentID,
+		UserID:       userID,
+		ContentType:  img.ContentType,
+		SizeBytes:    int32(len(img.Data)),
+		Width:        int32(img.Width),
+		Height:       int32(img.Height),
+		BlobKey:      blobKey,
+		ThumbnailKey: thumbnailKey,
+	})
+	if err != nil {
+		cfg.blobStore.Delete(r.Context(), blobKey)
+		cfg.blobStore.Delete(r.Context(), thumbnailKey)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save attachment", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, cfg.databaseAttachmentToAttachment(attachment))
+}
+
+// handlerMediaGet serves an attachment's image or thumbnail by blob key.
+// Only blobs on a chirp the viewer can see, and avatars, are served;
+// everything else in the blob store, such as uploads that were never
+// attached, reads as not found.
+func (cfg *apiConfig) handlerMediaGet(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	row, err := cfg.db.GetAttachmentByKey(r.Context(), key)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Media not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media", err)
+		return
+	}
+	attachment := row.Attachment
+
+	switch {
+	case attachment.ChirpID.Valid:
+		chirp, err := cfg.db.GetChirp(r.Context(), attachment.ChirpID.UUID)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Media not found", err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get media", err)
+			return
+		}
+		if chirp.PublishAt.Valid || chirp.DeletedAt.Valid || !chirpVisibleTo(chirp, cfg.viewerID(r)) {
+			respondWithError(w, http.StatusNotFound, "Media not found", nil)
+			return
+		}
+	case !row.IsAvatar:
+		respondWithError(w, http.StatusNotFound, "Media not found", nil)
+		return
+	}
+
+	blob, err := cfg.blobStore.Open(r.Context(), key)
+	if errors.Is(err, os.ErrNotExist) {
+		respondWithError(w, http.StatusNotFound, "Media not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media", err)
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, key, attachment.CreatedAt, blob)
+}
+
+func (cfg *apiConfig) databaseAttachmentToAttachment(attachment database.Attachment) Attachment {
+	return Attachment{
+		ID:           attachment.ID,
+		URL:          cfg.blobStore.URL(attachment.BlobKey),
+		ThumbnailURL: cfg.blobStore.URL(attachment.ThumbnailKey),
+		ContentType:  attachment.ContentType,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+	}
+}
+
+// deleteAttachmentBlobs removes an attachment's files once its row is gone.
+// Failures are only logged; a leftover file is harmless.
+func (cfg *apiConfig) deleteAttachmentBlobs(ctx context.Context, attachment database.Attachment) {
+	for _, key := range []string{attachment.BlobKey, attachment.ThumbnailKey} {
+		err := cfg.blobStore.Delete(ctx, key)
+		if err != nil {
+			log.Printf("Error deleting blob %s: %s", key, err)
+		}
+	}
+}
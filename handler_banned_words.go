@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/moderation"
+)
+
+// bannedWordActions maps the actions stored in banned_words onto moderation
+// actions: mask replaces the word with ****, reject refuses the chirp and
+// flag holds it for review.
+var bannedWordActions = map[string]moderation.Action{
+	"mask":   moderation.Redact,
+	"reject": moderation.Reject,
+	"flag":   moderation.Hold,
+}
+
+type BannedWord struct {
+	Term      string     `json:"term"`
+	Action    string     `json:"action"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+}
+
+func databaseBannedWordToBannedWord(word database.BannedWord) BannedWord {
+	converted := BannedWord{
+		Term:      word.Term,
+		Action:    word.Action,
+		CreatedAt: word.CreatedAt,
+		UpdatedAt: word.UpdatedAt,
+	}
+	if word.CreatedBy.Valid {
+		createdBy := word.CreatedBy.UUID
+		converted.CreatedBy = &createdBy
+	}
+	return converted
+}
+
+// reloadBannedWords replaces the in-memory banned word list with what is in
+// the database. It runs at startup and after every change made through the
+// admin API.
+func (cfg *apiConfig) reloadBannedWords(ctx context.Context) error {
+	words, err := cfg.db.ListBannedWords(ctx)
+	if err != nil {
+		return err
+	}
+	terms := make(map[string]moderation.Action, len(words))
+	for _, word := range words {
+		action, err := parseBannedWordAction(word.Action)
+		if err != nil {
+			return fmt.Errorf("banned word %q: %w", word.Term, err)
+		}
+		terms[word.Term] = action
+	}
+	cfg.bannedWords.Store(terms)
+	return nil
+}
+
+func parseBannedWordAction(s string) (moderation.Action, error) {
+	action, ok := bannedWordActions[s]
+	if !ok {
+		return moderation.Allow, fmt.Errorf("unknown banned word action %q", s)
+	}
+	return action, nil
+}
+
+func (cfg *apiConfig) handlerBannedWordsGet(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	words, err := cfg.db.ListBannedWords(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get banned words", err)
+		return
+	}
+
+	listOfWords := []BannedWord{}
+	for _, word := range words {
+		listOfWords = append(listOfWords, databaseBannedWordToBannedWord(word))
+	}
+
+	respondWithJSON(w, http.StatusOK, listOfWords)
+}
+
+func (cfg *apiConfig) handlerBannedWordsCreate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Term   string `json:"term"`
+		Action string `json:"action"`
+	}
+
+	adminID, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	term, err := moderation.NormalizeTerm(params.Term)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Term must be a single word", err)
+		return
+	}
+
+	if params.Action == "" {
+		params.Action = "mask"
+	}
+	_, err = parseBannedWordAction(params.Action)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Action must be one of mask, reject or flag", err)
+		return
+	}
+
+	word, err := cfg.db.UpsertBannedWord(r.Context(), database.UpsertBannedWordParams{
+		Term:      term,
+		Action:    params.Action,
+		CreatedBy: uuid.NullUUID{UUID: adminID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save banned word", err)
+		return
+	}
+
+	err = cfg.reloadBannedWords(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload banned words", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseBannedWordToBannedWord(word))
+}
+
+func (cfg *apiConfig) handlerBannedWordsDelete(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	term, err := moderation.NormalizeTerm(vars["term"])
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Banned word not found", err)
+		return
+	}
+
+	deleted, err := cfg.db.DeleteBannedWord(r.Context(), term)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete banned word", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Banned word not found", nil)
+		return
+	}
+
+	err = cfg.reloadBannedWords(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload banned words", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerBookmarkCreate(w http.ResponseWriter, r *http.Request) {
+	userID, chirpID, ok := cfg.bookmarkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err == nil && (chirp.PublishAt.Valid || !chirpVisibleTo(chirp, userID)) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+
+	err = cfg.db.BookmarkChirp(r.Context(), database.BookmarkChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't bookmark chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerBookmarkDelete(w http.ResponseWriter, r *http.Request) {
+	userID, chirpID, ok := cfg.bookmarkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Removing a bookmark doesn't check the chirp, so bookmarks on chirps
+	// that have since been hidden or deleted can still be cleared.
+	err := cfg.db.UnbookmarkChirp(r.Context(), database.UnbookmarkChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove bookmark", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerBookmarksGet lists the caller's bookmarks, most recently saved
+// first. Bookmarks are private, so there is no way to list another user's.
+func (cfg *apiConfig) handlerBookmarksGet(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Could not get Token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	rows, err := cfg.db.ListBookmarkedChirps(r.Context(), database.ListBookmarkedChirpsParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get bookmarks", err)
+		return
+	}
+
+	// The cursor holds when the chirp was bookmarked, not when it was posted.
+	rows, nextCursor := paginate(rows, page, func(row database.ListBookmarkedChirpsRow) (time.Time, uuid.UUID) {
+		return row.BookmarkedAt, row.Chirp.ID
+	})
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+
+	listOfChirps, err := cfg.chirpsToResponse(r.Context(), userID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get bookmarks", err)
+		return
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     listOfChirps,
+		NextCursor: nextCursor,
+	})
+}
+
+// bookmarkRequest authenticates the caller and parses the chirp ID from
+// the path. It doesn't look the chirp up; that is left to the handlers.
+func (cfg *apiConfig) bookmarkRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Could not get Token", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, chirpID, true
+}
@@ -1,30 +1,62 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/chirptext"
 	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/moderation"
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID               uuid.UUID    `json:"id"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+	Body             string       `json:"body"`
+	UserID           uuid.UUID    `json:"user_id"`
+	ModerationStatus string       `json:"moderation_status"`
+	PublishAt        *time.Time   `json:"publish_at,omitempty"`
+	InReplyTo        *uuid.UUID   `json:"in_reply_to"`
+	Hashtags         []string     `json:"hashtags"`
+	Mentions         []Mention    `json:"mentions"`
+	Attachments      []Attachment `json:"attachments"`
+	Poll             *Poll        `json:"poll"`
+	RechirpOf        *Chirp       `json:"rechirp_of"`
+	QuotedChirp      *Chirp       `json:"quoted_chirp"`
+	ReplyCount       int64        `json:"reply_count"`
+	LikeCount        int64        `json:"like_count"`
+	RechirpCount     int64        `json:"rechirp_count"`
+	LikedByMe        bool         `json:"liked_by_me"`
+	Bookmarked       bool         `json:"bookmarked"`
+	Pinned           bool         `json:"pinned"`
+}
+
+// Mention is an @handle in a chirp body that resolved to a user. Start and
+// End are offsets into the body in Unicode code points, End exclusive.
+type Mention struct {
+	UserID uuid.UUID `json:"user_id"`
+	Handle string    `json:"handle"`
+	Start  int32     `json:"start"`
+	End    int32     `json:"end"`
 }
 
 func (cfg *apiConfig) handlerChirpsCreate(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Body   string    `json:"body"`
-		UserID uuid.UUID `json:"user_id"`
+		Body          string          `json:"body"`
+		UserID        uuid.UUID       `json:"user_id"`
+		InReplyTo     *uuid.UUID      `json:"in_reply_to"`
+		QuoteOf       *uuid.UUID      `json:"quote_of"`
+		AttachmentIDs []uuid.UUID     `json:"attachment_ids"`
+		PublishAt     *time.Time      `json:"publish_at"`
+		Poll          *pollParameters `json:"poll"`
 	}
 
 	tokenString, err := auth.GetBearerToken(r.Header)
@@ -47,153 +79,280 @@ func (cfg *apiConfig) handlerChirpsCreate(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	cleaned, err := validateChirp(params.Body)
+	if !cfg.requirePostingAllowed(w, r, id) {
+		return
+	}
+
+	verdict, err := cfg.validateChirp(params.Body)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, err.Error(), err)
 		return
 	}
 
-	chirp, err := cfg.db.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   cleaned,
-		UserID: id,
-	})
+	if len(params.AttachmentIDs) > maxChirpAttachments {
+		respondWithError(w, http.StatusBadRequest, "Too many attachments", nil)
+		return
+	}
+
+	publishAt := sql.NullTime{}
+	if params.PublishAt != nil {
+		now := time.Now().UTC()
+		if !params.PublishAt.After(now) {
+			respondWithError(w, http.StatusBadRequest, "publish_at must be in the future", nil)
+			return
+		}
+		if params.PublishAt.After(now.Add(maxScheduleAhead)) {
+			respondWithError(w, http.StatusBadRequest, "publish_at is too far in the future", nil)
+			return
+		}
+		publishAt = sql.NullTime{Time: params.PublishAt.UTC(), Valid: true}
+	}
+
+	var pollLabels []string
+	if params.Poll != nil {
+		opensAt := time.Now().UTC()
+		if publishAt.Valid {
+			opensAt = publishAt.Time
+		}
+		pollLabels, verdict, err = cfg.validatePoll(*params.Poll, opensAt, verdict)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	inReplyTo, err := cfg.chirpReference(r.Context(), params.InReplyTo, id)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Chirp being replied to does not exist", err)
+		return
+	}
+
+	quoteOf, err := cfg.chirpReference(r.Context(), params.QuoteOf, id)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Chirp being quoted does not exist", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	respondWithJSON(w, http.StatusCreated, Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
+	chirp, err := insertChirp(r.Context(), qtx, verdict, database.CreateChirpParams{
+		UserID:    id,
+		InReplyTo: inReplyTo,
+		QuoteOf:   quoteOf,
+		PublishAt: publishAt,
 	})
-}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
 
-func validateChirp(body string) (string, error) {
-	const maxChirpLength = 140
-	if len(body) > maxChirpLength {
-		return "", errors.New("Chirp is too long")
+	if params.Poll != nil {
+		err = insertPoll(r.Context(), qtx, chirp.ID, params.Poll.ClosesAt, pollLabels)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't create poll", err)
+			return
+		}
 	}
 
-	badWords := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+	for i, attachmentID := range params.AttachmentIDs {
+		attached, err := qtx.AttachToChirp(r.Context(), database.AttachToChirpParams{
+			ChirpID:  uuid.NullUUID{UUID: chirp.ID, Valid: true},
+			Position: int32(i),
+			ID:       attachmentID,
+			UserID:   id,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't attach file", err)
+			return
+		}
+		if attached == 0 {
+			respondWithError(w, http.StatusBadRequest, "Attachment not found or already used", nil)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
+
+	response, err := cfg.chirpToResponse(r.Context(), id, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// chirpReference resolves the chirp a new chirp replies to or quotes,
+// following rechirps to their original. Chirps the author can't see are
+// reported as sql.ErrNoRows.
+func (cfg *apiConfig) chirpReference(ctx context.Context, chirpID *uuid.UUID, viewerID uuid.UUID) (uuid.NullUUID, error) {
+	if chirpID == nil {
+		return uuid.NullUUID{}, nil
 	}
-	cleaned := getCleanedBody(body, badWords)
-	return cleaned, nil
+	chirp, err := cfg.db.GetChirp(ctx, *chirpID)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if !chirpVisibleTo(chirp, viewerID) {
+		return uuid.NullUUID{}, sql.ErrNoRows
+	}
+	return uuid.NullUUID{UUID: originalChirpID(chirp), Valid: true}, nil
 }
 
-func getCleanedBody(body string, badWords map[string]struct{}) string {
-	words := strings.Split(body, " ")
-	for i, word := range words {
-		loweredWord := strings.ToLower(word)
-		if _, ok := badWords[loweredWord]; ok {
-			words[i] = "****"
+// insertChirp creates a chirp with the body from a moderation verdict and
+// saves its hashtags and mentions. A held chirp is also put in the review
+// queue. q must be bound to a transaction.
+func insertChirp(ctx context.Context, q *database.Queries, verdict moderation.Verdict, params database.CreateChirpParams) (database.Chirp, error) {
+	params.Body = verdict.Body
+	params.ModerationStatus = chirpStatusVisible
+	if verdict.Action == moderation.Hold {
+		params.ModerationStatus = chirpStatusHeld
+	}
+
+	chirp, err := q.CreateChirp(ctx, params)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	err = saveChirpEntities(ctx, q, chirp)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	if chirp.ModerationStatus == chirpStatusHeld {
+		err = reportHeldChirp(ctx, q, chirp, verdict.Reasons)
+		if err != nil {
+			return database.Chirp{}, err
 		}
 	}
-	cleaned := strings.Join(words, " ")
-	return cleaned
+	return chirp, nil
+}
+
+// validateChirp normalizes a body and checks it against the length limit
+// and the moderation filters. Rejected bodies are an error; otherwise the
+// verdict carries the body to store and whether it must be held for review.
+func (cfg *apiConfig) validateChirp(body string) (moderation.Verdict, error) {
+	body = chirptext.Normalize(body)
+	if chirptext.Length(body) > cfg.maxChirpLength {
+		return moderation.Verdict{}, errors.New("Chirp is too long")
+	}
+
+	verdict := cfg.chirpFilter.Check(body)
+	if verdict.Action == moderation.Reject {
+		return moderation.Verdict{}, errors.New("Chirp violates the content rules")
+	}
+	return verdict, nil
+}
+
+type ChirpPage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
 
 func (cfg *apiConfig) handlerChirpsGet(w http.ResponseWriter, r *http.Request) {
 	authorID := r.URL.Query().Get("author_id")
 	chirpsSort := r.URL.Query().Get("sort")
 
-	if authorID == "" {
-
-		if chirpsSort == "" || chirpsSort == "asc" {
-			chirps, err := cfg.db.GetChirps(r.Context())
-			if err != nil {
-				respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
-				return
-			}
-			listOfChirps := []Chirp{}
-			for _, chi := range chirps {
-				convertedChirp := Chirp{
-					ID:        chi.ID,
-					CreatedAt: chi.CreatedAt,
-					UpdatedAt: chi.UpdatedAt,
-					Body:      chi.Body,
-					UserID:    chi.UserID,
-				}
-				listOfChirps = append(listOfChirps, convertedChirp)
-			}
-			respondWithJSON(w, http.StatusOK, listOfChirps)
-			return
-		}
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
 
-		chirps, err := cfg.db.GetChirpsDesc(r.Context())
+	authorFilter := uuid.NullUUID{}
+	if authorID != "" {
+		parsedAuthorID, err := uuid.Parse(authorID)
 		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
+			respondWithError(w, http.StatusBadRequest, "Couldn't parse author ID", err)
 			return
 		}
-		listOfChirps := []Chirp{}
-		for _, chi := range chirps {
-			convertedChirp := Chirp{
-				ID:        chi.ID,
-				CreatedAt: chi.CreatedAt,
-				UpdatedAt: chi.UpdatedAt,
-				Body:      chi.Body,
-				UserID:    chi.UserID,
-			}
-			listOfChirps = append(listOfChirps, convertedChirp)
-		}
-		respondWithJSON(w, http.StatusOK, listOfChirps)
-		return
+		authorFilter = uuid.NullUUID{UUID: parsedAuthorID, Valid: true}
 	}
 
-	parsedAuthorID, err := uuid.Parse(authorID)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't parse author ID", err)
-		return
-	}
+	viewerID := cfg.viewerID(r)
 
-	if chirpsSort == "" || chirpsSort == "asc" {
-		chirps, err := cfg.db.GetChirpsByUser(r.Context(), parsedAuthorID)
+	// An author's pinned chirp leads the first page of their chirps and is
+	// left out of the pages themselves so it isn't listed twice. It takes one
+	// of the first page's slots, so with a limit of 1 the chirps are listed
+	// without it.
+	var pinned *database.Chirp
+	excludeID := uuid.NullUUID{}
+	if authorFilter.Valid && page.Limit > 1 {
+		author, err := cfg.db.GetUserByID(r.Context(), authorFilter.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
+			return
+		}
+		pinned, err = cfg.pinnedChirp(r.Context(), author, viewerID)
 		if err != nil {
 			respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
 			return
 		}
-
-		listOfChirps := []Chirp{}
-		for _, chi := range chirps {
-			convertedChirp := Chirp{
-				ID:        chi.ID,
-				CreatedAt: chi.CreatedAt,
-				UpdatedAt: chi.UpdatedAt,
-				Body:      chi.Body,
-				UserID:    chi.UserID,
-			}
-			listOfChirps = append(listOfChirps, convertedChirp)
+		if pinned != nil {
+			excludeID = uuid.NullUUID{UUID: pinned.ID, Valid: true}
 		}
+	}
+	leadWithPin := pinned != nil && page.Cursor == nil
+	if leadWithPin {
+		page.Limit--
+	}
 
-		respondWithJSON(w, http.StatusOK, listOfChirps)
-		return
+	var chirps []database.Chirp
+	if chirpsSort == "" || chirpsSort == "asc" {
+		chirps, err = cfg.db.ListChirps(r.Context(), database.ListChirpsParams{
+			AuthorID:        authorFilter,
+			ViewerID:        nullViewerID(viewerID),
+			ExcludeID:       excludeID,
+			CursorCreatedAt: page.cursorCreatedAt(),
+			CursorID:        page.cursorID(),
+			PageLimit:       page.fetchLimit(),
+		})
+	} else {
+		chirps, err = cfg.db.ListChirpsDesc(r.Context(), database.ListChirpsDescParams{
+			AuthorID:        authorFilter,
+			ViewerID:        nullViewerID(viewerID),
+			ExcludeID:       excludeID,
+			CursorCreatedAt: page.cursorCreatedAt(),
+			CursorID:        page.cursorID(),
+			PageLimit:       page.fetchLimit(),
+		})
 	}
-	chirps, err := cfg.db.GetChirpsByUserDesc(r.Context(), parsedAuthorID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
 		return
 	}
 
-	listOfChirps := []Chirp{}
-	for _, chi := range chirps {
-		convertedChirp := Chirp{
-			ID:        chi.ID,
-			CreatedAt: chi.CreatedAt,
-			UpdatedAt: chi.UpdatedAt,
-			Body:      chi.Body,
-			UserID:    chi.UserID,
-		}
-		listOfChirps = append(listOfChirps, convertedChirp)
+	chirps, nextCursor := paginate(chirps, page, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+	if leadWithPin {
+		chirps = append([]database.Chirp{*pinned}, chirps...)
 	}
 
-	respondWithJSON(w, http.StatusOK, listOfChirps)
-	return
+	listOfChirps, err := cfg.chirpsToResponse(r.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
+		return
+	}
+	if leadWithPin {
+		listOfChirps[0].Pinned = true
+	}
 
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     listOfChirps,
+		NextCursor: nextCursor,
+	})
 }
 
 func (cfg *apiConfig) handlerChirpsID(w http.ResponseWriter, r *http.Request) {
@@ -201,19 +360,24 @@ func (cfg *apiConfig) handlerChirpsID(w http.ResponseWriter, r *http.Request) {
 	chirpID, err := uuid.Parse(vars["chirpID"])
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
 	}
 	chirp, err := cfg.db.GetChirp(r.Context(), chirpID)
 
+	viewerID := cfg.viewerID(r)
+	if err == nil && !chirpVisibleTo(chirp, viewerID) {
+		err = sql.ErrNoRows
+	}
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
-	})
+	response, err := cfg.chirpToResponse(r.Context(), viewerID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
 }
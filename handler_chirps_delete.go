@@ -1,17 +1,18 @@
 package main
 
 import (
-	"fmt"
+	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
 )
 
 func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, r *http.Request) {
 	bearerToken, err := auth.GetBearerToken(r.Header)
-	fmt.Println(bearerToken)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
 		return
@@ -41,9 +42,13 @@ func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = cfg.db.DeleteChirp(r.Context(), chirpID)
+	err = cfg.db.DeleteChirp(r.Context(), database.DeleteChirpParams{
+		ID:        chirpID,
+		DeletedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
 	if err != nil {
-		respondWithError(w, http.StatusForbidden, "Cannot delete Chirp", err)
+		respondWithError(w, http.StatusInternalServerError, "Cannot delete Chirp", err)
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
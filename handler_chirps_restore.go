@@ -0,0 +1,148 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// handlerChirpsRestore brings back a chirp its author deleted, as long as
+// the purge job hasn't removed it yet.
+func (cfg *apiConfig) handlerChirpsRestore(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetDeletedChirp(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Deleted chirp not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	if userID != chirp.UserID {
+		respondWithError(w, http.StatusForbidden, "Incorrect author of Chirp", nil)
+		return
+	}
+
+	if chirp.ModerationStatus == chirpStatusHidden {
+		respondWithError(w, http.StatusForbidden, "Chirp was removed by a moderator", nil)
+		return
+	}
+
+	if chirp.DeletedAt.Time.Before(time.Now().UTC().Add(-cfg.chirpRetention)) {
+		respondWithError(w, http.StatusGone, "Chirp can no longer be restored", nil)
+		return
+	}
+
+	// A rechirp can't outlive the chirp it repeats.
+	if chirp.RechirpOf.Valid {
+		_, err = cfg.db.GetChirp(r.Context(), chirp.RechirpOf.UUID)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusConflict, "Original chirp was deleted", err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+			return
+		}
+	}
+
+	err = cfg.db.RestoreChirp(r.Context(), chirpID)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Chirp already rechirped", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+
+	restored, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	response, err := cfg.chirpToResponse(r.Context(), userID, restored)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// handlerTrashGet lists the caller's deleted chirps that can still be
+// restored, newest first.
+func (cfg *apiConfig) handlerTrashGet(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	chirps, err := cfg.db.ListDeletedChirps(r.Context(), database.ListDeletedChirpsParams{
+		UserID:          userID,
+		DeletedAfter:    sql.NullTime{Time: time.Now().UTC().Add(-cfg.chirpRetention), Valid: true},
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get deleted chirps", err)
+		return
+	}
+
+	chirps, nextCursor := paginate(chirps, page, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	listOfChirps, err := cfg.chirpsToResponse(r.Context(), userID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get deleted chirps", err)
+		return
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     listOfChirps,
+		NextCursor: nextCursor,
+	})
+}
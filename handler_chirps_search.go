@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/search"
+)
+
+// ChirpSearchResult is a chirp matching a search. Snippet is HTML: the
+// chirp text is escaped and the matching words are wrapped in <mark> tags.
+type ChirpSearchResult struct {
+	Chirp
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// maxSearchOffset is how deep into the results search will page. Deep
+// offsets make Postgres rank and skip every row before them.
+const maxSearchOffset = 10000
+
+type ChirpSearchPage struct {
+	Results    []ChirpSearchResult `json:"results"`
+	NextOffset int                 `json:"next_offset,omitempty"`
+}
+
+func (cfg *apiConfig) handlerChirpsSearch(w http.ResponseWriter, r *http.Request) {
+	query, err := search.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// Results are ordered by rank, which has no stable keyset, so search
+	// pages by offset instead of cursor.
+	offset := 0
+	if rawOffset := r.URL.Query().Get("offset"); rawOffset != "" {
+		offset, err = strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset", err)
+			return
+		}
+		if offset > maxSearchOffset {
+			respondWithError(w, http.StatusBadRequest, "Offset is too large", nil)
+			return
+		}
+	}
+
+	authorFilter := uuid.NullUUID{}
+	if authorID := r.URL.Query().Get("author_id"); authorID != "" {
+		parsedAuthorID, err := uuid.Parse(authorID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Couldn't parse author ID", err)
+			return
+		}
+		authorFilter = uuid.NullUUID{UUID: parsedAuthorID, Valid: true}
+	}
+
+	viewerID := cfg.viewerID(r)
+	rows, err := cfg.db.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Query:      query,
+		AuthorID:   authorFilter,
+		ViewerID:   nullViewerID(viewerID),
+		PageLimit:  page.fetchLimit(),
+		PageOffset: int32(offset),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+
+	nextOffset := 0
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		if offset+int(page.Limit) <= maxSearchOffset {
+			nextOffset = offset + int(page.Limit)
+		}
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+	converted, err := cfg.chirpsToResponse(r.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+
+	results := []ChirpSearchResult{}
+	for i, row := range rows {
+		results = append(results, ChirpSearchResult{
+			Chirp:   converted[i],
+			Rank:    row.Rank,
+			Snippet: row.Snippet,
+		})
+	}
+
+	if nextOffset > 0 {
+		setNextLinkParam(w, r, "offset", strconv.Itoa(nextOffset))
+	}
+	respondWithJSON(w, http.StatusOK, ChirpSearchPage{
+		Results:    results,
+		NextOffset: nextOffset,
+	})
+}
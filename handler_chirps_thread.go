@@ -0,0 +1,159 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+const (
+	defaultThreadDepth = 3
+	maxThreadDepth     = 10
+	maxAncestorDepth   = 100
+	// maxThreadReplies caps the replies in one response across every
+	// level. The shallowest replies are kept, so a direct reply always
+	// arrives before any of its own replies are cut; the rest of a long
+	// branch can be read from that reply's own thread.
+	maxThreadReplies = 500
+)
+
+type ChirpReply struct {
+	Chirp
+	Replies []ChirpReply `json:"replies"`
+}
+
+type ChirpThread struct {
+	Ancestors  []Chirp      `json:"ancestors"`
+	Chirp      Chirp        `json:"chirp"`
+	Replies    []ChirpReply `json:"replies"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerChirpsThread(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	depth := defaultThreadDepth
+	if rawDepth := r.URL.Query().Get("depth"); rawDepth != "" {
+		depth, err = strconv.Atoi(rawDepth)
+		if err != nil || depth < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid depth", err)
+			return
+		}
+		if depth > maxThreadDepth {
+			depth = maxThreadDepth
+		}
+	}
+
+	viewerID := cfg.viewerID(r)
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err == nil && !chirpVisibleTo(chirp, viewerID) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+
+	allAncestors, err := cfg.db.GetChirpAncestors(r.Context(), database.GetChirpAncestorsParams{
+		ChirpID:  chirpID,
+		MaxDepth: maxAncestorDepth,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get thread", err)
+		return
+	}
+	ancestors := []database.Chirp{}
+	for _, ancestor := range allAncestors {
+		if chirpVisibleTo(ancestor, viewerID) {
+			ancestors = append(ancestors, ancestor)
+		}
+	}
+
+	tree, err := cfg.db.GetChirpReplyTree(r.Context(), database.GetChirpReplyTreeParams{
+		ChirpID:         chirpID,
+		ViewerID:        nullViewerID(viewerID),
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+		MaxDepth:        int32(depth),
+		MaxReplies:      maxThreadReplies,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get thread", err)
+		return
+	}
+
+	// Only direct replies are paginated. Replies the viewer can't see are
+	// left out by the query together with everything beneath them, so they
+	// don't use up the page. The extra one fetched to detect a next page is
+	// dropped together with everything beneath it.
+	directReplies := []database.Chirp{}
+	for _, row := range tree {
+		if row.Depth == 1 {
+			directReplies = append(directReplies, row.Chirp)
+		}
+	}
+	nextCursor := ""
+	dropped := uuid.Nil
+	if len(directReplies) > int(page.Limit) {
+		dropped = directReplies[page.Limit].ID
+		last := directReplies[page.Limit-1]
+		nextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	included := map[uuid.UUID]bool{chirpID: true}
+	replies := []database.Chirp{}
+	for _, row := range tree {
+		if row.Chirp.ID == dropped || !row.Chirp.InReplyTo.Valid || !included[row.Chirp.InReplyTo.UUID] {
+			continue
+		}
+		included[row.Chirp.ID] = true
+		replies = append(replies, row.Chirp)
+	}
+
+	all := append(append(append([]database.Chirp{}, ancestors...), chirp), replies...)
+	converted, err := cfg.chirpsToResponse(r.Context(), viewerID, all)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get thread", err)
+		return
+	}
+
+	children := map[uuid.UUID][]Chirp{}
+	for _, reply := range converted[len(ancestors)+1:] {
+		children[*reply.InReplyTo] = append(children[*reply.InReplyTo], reply)
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ChirpThread{
+		Ancestors:  converted[:len(ancestors)],
+		Chirp:      converted[len(ancestors)],
+		Replies:    buildReplyTree(chirpID, children),
+		NextCursor: nextCursor,
+	})
+}
+
+func buildReplyTree(parentID uuid.UUID, children map[uuid.UUID][]Chirp) []ChirpReply {
+	replies := []ChirpReply{}
+	for _, child := range children[parentID] {
+		replies = append(replies, ChirpReply{
+			Chirp:   child,
+			Replies: buildReplyTree(child.ID, children),
+		})
+	}
+	return replies
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/moderation"
+)
+
+func (cfg *apiConfig) handlerChirpsUpdate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	if !cfg.requirePostingAllowed(w, r, userID) {
+		return
+	}
+
+	verdict, err := cfg.validateChirp(params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.GetChirpForUpdate(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	if userID != chirp.UserID {
+		respondWithError(w, http.StatusForbidden, "Incorrect author of Chirp", nil)
+		return
+	}
+
+	if chirp.RechirpOf.Valid {
+		respondWithError(w, http.StatusBadRequest, "Rechirps can't be edited", nil)
+		return
+	}
+
+	if chirp.Body == verdict.Body {
+		response, err := cfg.chirpToResponse(r.Context(), userID, chirp)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+			return
+		}
+		respondWithJSON(w, http.StatusOK, response)
+		return
+	}
+
+	err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+		ChirpID:   chirp.ID,
+		Body:      chirp.Body,
+		CreatedAt: chirp.UpdatedAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp revision", err)
+		return
+	}
+
+	// An edit can put a chirp on hold but never releases one; that is
+	// left to a moderator.
+	moderationStatus := chirp.ModerationStatus
+	if verdict.Action == moderation.Hold {
+		moderationStatus = chirpStatusHeld
+	}
+
+	updated, err := qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+		ID:               chirp.ID,
+		Body:             verdict.Body,
+		ModerationStatus: moderationStatus,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	err = saveChirpEntities(r.Context(), qtx, updated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	if verdict.Action == moderation.Hold {
+		err = reportHeldChirp(r.Context(), qtx, updated, verdict.Reasons)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	response, err := cfg.chirpToResponse(r.Context(), userID, updated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// maxDraftLength is in bytes and only guards storage. Drafts may run over
+// the chirp length limit; that is checked when they are published.
+const maxDraftLength = 4096
+
+type Draft struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	InReplyTo *uuid.UUID `json:"in_reply_to"`
+	QuoteOf   *uuid.UUID `json:"quote_of"`
+}
+
+type DraftPage struct {
+	Drafts     []Draft `json:"drafts"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type draftParameters struct {
+	Body      string     `json:"body"`
+	InReplyTo *uuid.UUID `json:"in_reply_to"`
+	QuoteOf   *uuid.UUID `json:"quote_of"`
+}
+
+func databaseDraftToDraft(draft database.Draft) Draft {
+	converted := Draft{
+		ID:        draft.ID,
+		CreatedAt: draft.CreatedAt,
+		UpdatedAt: draft.UpdatedAt,
+		Body:      draft.Body,
+	}
+	if draft.InReplyTo.Valid {
+		inReplyTo := draft.InReplyTo.UUID
+		converted.InReplyTo = &inReplyTo
+	}
+	if draft.QuoteOf.Valid {
+		quoteOf := draft.QuoteOf.UUID
+		converted.QuoteOf = &quoteOf
+	}
+	return converted
+}
+
+func toNullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func (cfg *apiConfig) handlerDraftsCreate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.draftsUser(w, r)
+	if !ok {
+		return
+	}
+
+	params, ok := decodeDraftParameters(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.db.CreateDraft(r.Context(), database.CreateDraftParams{
+		UserID:    userID,
+		Body:      params.Body,
+		InReplyTo: toNullUUID(params.InReplyTo),
+		QuoteOf:   toNullUUID(params.QuoteOf),
+	})
+	if isForeignKeyViolation(err) {
+		respondWithError(w, http.StatusBadRequest, "Referenced chirp does not exist", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create draft", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseDraftToDraft(draft))
+}
+
+func (cfg *apiConfig) handlerDraftsGet(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.draftsUser(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	drafts, err := cfg.db.ListDrafts(r.Context(), database.ListDraftsParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get drafts", err)
+		return
+	}
+
+	// Drafts are listed most recently edited first, so the cursor holds
+	// updated_at.
+	drafts, nextCursor := paginate(drafts, page, func(draft database.Draft) (time.Time, uuid.UUID) {
+		return draft.UpdatedAt, draft.ID
+	})
+
+	listOfDrafts := []Draft{}
+	for _, draft := range drafts {
+		listOfDrafts = append(listOfDrafts, databaseDraftToDraft(draft))
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, DraftPage{
+		Drafts:     listOfDrafts,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerDraftsID(w http.ResponseWriter, r *http.Request) {
+	userID, draftID, ok := cfg.draftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.db.GetDraft(r.Context(), database.GetDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Draft not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get draft", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseDraftToDraft(draft))
+}
+
+func (cfg *apiConfig) handlerDraftsUpdate(w http.ResponseWriter, r *http.Request) {
+	userID, draftID, ok := cfg.draftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	params, ok := decodeDraftParameters(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.db.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		ID:        draftID,
+		UserID:    userID,
+		Body:      params.Body,
+		InReplyTo: toNullUUID(params.InReplyTo),
+		QuoteOf:   toNullUUID(params.QuoteOf),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Draft not found", err)
+		return
+	}
+	if isForeignKeyViolation(err) {
+		respondWithError(w, http.StatusBadRequest, "Referenced chirp does not exist", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update draft", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseDraftToDraft(draft))
+}
+
+func (cfg *apiConfig) handlerDraftsDelete(w http.ResponseWriter, r *http.Request) {
+	userID, draftID, ok := cfg.draftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete draft", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Draft not found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerDraftsPublish turns a draft into a chirp. The chirp is created and
+// the draft deleted in one transaction, so a draft is never published twice
+// or lost.
+func (cfg *apiConfig) handlerDraftsPublish(w http.ResponseWriter, r *http.Request) {
+	userID, draftID, ok := cfg.draftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if !cfg.requirePostingAllowed(w, r, userID) {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	draft, err := qtx.GetDraftForUpdate(r.Context(), database.GetDraftForUpdateParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Draft not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get draft", err)
+		return
+	}
+
+	verdict, err := cfg.validateChirp(draft.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	var replyTarget, quoteTarget *uuid.UUID
+	if draft.InReplyTo.Valid {
+		replyTarget = &draft.InReplyTo.UUID
+	}
+	if draft.QuoteOf.Valid {
+		quoteTarget = &draft.QuoteOf.UUID
+	}
+
+	inReplyTo, err := cfg.chirpReference(r.Context(), replyTarget, userID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Chirp being replied to does not exist", err)
+		return
+	}
+
+	quoteOf, err := cfg.chirpReference(r.Context(), quoteTarget, userID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Chirp being quoted does not exist", err)
+		return
+	}
+
+	chirp, err := insertChirp(r.Context(), qtx, verdict, database.CreateChirpParams{
+		UserID:    userID,
+		InReplyTo: inReplyTo,
+		QuoteOf:   quoteOf,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		return
+	}
+
+	_, err = qtx.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draft.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		return
+	}
+
+	response, err := cfg.chirpToResponse(r.Context(), userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+func (cfg *apiConfig) draftsUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return uuid.Nil, false
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func (cfg *apiConfig) draftRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := cfg.draftsUser(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	vars := mux.Vars(r)
+	draftID, err := uuid.Parse(vars["draftID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, draftID, true
+}
+
+func decodeDraftParameters(w http.ResponseWriter, r *http.Request) (draftParameters, bool) {
+	decoder := json.NewDecoder(r.Body)
+	params := draftParameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return draftParameters{}, false
+	}
+	if len(params.Body) > maxDraftLength {
+		respondWithError(w, http.StatusBadRequest, "Draft is too long", nil)
+		return draftParameters{}, false
+	}
+	return params, true
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+type Follow struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type FollowPage struct {
+	Users      []Follow `json:"users"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerFollowCreate(w http.ResponseWriter, r *http.Request) {
+	followerID, followeeID, ok := cfg.followRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.FollowUser(r.Context(), database.FollowUserParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't follow user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerFollowDelete(w http.ResponseWriter, r *http.Request) {
+	followerID, followeeID, ok := cfg.followRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.UnfollowUser(r.Context(), database.UnfollowUserParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unfollow user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// followRequest authenticates the caller and resolves the user being
+// followed or unfollowed, writing the error response itself on failure.
+func (cfg *apiConfig) followRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	followerID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	vars := mux.Vars(r)
+	followeeID, err := uuid.Parse(vars["userID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if followerID == followeeID {
+		respondWithError(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	_, err = cfg.db.GetUserByID(r.Context(), followeeID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return followerID, followeeID, true
+}
+
+func (cfg *apiConfig) handlerFollowersGet(w http.ResponseWriter, r *http.Request) {
+	userID, page, ok := cfg.followListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.db.ListFollowers(r.Context(), database.ListFollowersParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get followers", err)
+		return
+	}
+
+	follows := []Follow{}
+	for _, row := range rows {
+		follows = append(follows, Follow{UserID: row.UserID, FollowedAt: row.CreatedAt})
+	}
+	respondWithFollowPage(w, r, follows, page)
+}
+
+func (cfg *apiConfig) handlerFollowingGet(w http.ResponseWriter, r *http.Request) {
+	userID, page, ok := cfg.followListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.db.ListFollowing(r.Context(), database.ListFollowingParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get followed users", err)
+		return
+	}
+
+	follows := []Follow{}
+	for _, row := range rows {
+		follows = append(follows, Follow{UserID: row.UserID, FollowedAt: row.CreatedAt})
+	}
+	respondWithFollowPage(w, r, follows, page)
+}
+
+func (cfg *apiConfig) followListRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, pageParams, bool) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["userID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return uuid.Nil, pageParams{}, false
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return uuid.Nil, pageParams{}, false
+	}
+
+	_, err = cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return uuid.Nil, pageParams{}, false
+	}
+
+	return userID, page, true
+}
+
+func respondWithFollowPage(w http.ResponseWriter, r *http.Request, follows []Follow, page pageParams) {
+	follows, nextCursor := paginate(follows, page, func(follow Follow) (time.Time, uuid.UUID) {
+		return follow.FollowedAt, follow.UserID
+	})
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, FollowPage{
+		Users:      follows,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/chirptext"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+	defaultTrendingLimit  = 10
+)
+
+type TrendingHashtag struct {
+	Tag        string `json:"tag"`
+	ChirpCount int64  `json:"chirp_count"`
+}
+
+func (cfg *apiConfig) handlerHashtagChirpsGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tag := chirptext.NormalizeHashtag(vars["tag"])
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	chirps, err := cfg.db.ListChirpsByHashtag(r.Context(), database.ListChirpsByHashtagParams{
+		Tag:             tag,
+		ViewerID:        nullViewerID(viewerID),
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
+		return
+	}
+
+	chirps, nextCursor := paginate(chirps, page, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	listOfChirps, err := cfg.chirpsToResponse(r.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps", err)
+		return
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     listOfChirps,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerHashtagsTrending(w http.ResponseWriter, r *http.Request) {
+	window := defaultTrendingWindow
+	if rawWindow := r.URL.Query().Get("window"); rawWindow != "" {
+		parsed, err := time.ParseDuration(rawWindow)
+		if err != nil || parsed < time.Minute || parsed > maxTrendingWindow {
+			respondWithError(w, http.StatusBadRequest, "Invalid window", err)
+			return
+		}
+		window = parsed
+	}
+
+	limit := defaultTrendingLimit
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 1 || parsed > maxPageLimit {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+			return
+		}
+		limit = parsed
+	}
+
+	rows, err := cfg.db.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		WindowSeconds: int32(window.Seconds()),
+		PageLimit:     int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get trending hashtags", err)
+		return
+	}
+
+	trending := []TrendingHashtag{}
+	for _, row := range rows {
+		trending = append(trending, TrendingHashtag{
+			Tag:        row.Tag,
+			ChirpCount: row.ChirpCount,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, trending)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerLikeCreate(w http.ResponseWriter, r *http.Request) {
+	userID, chirpID, ok := cfg.likeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.LikeChirp(r.Context(), database.LikeChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't like chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerLikeDelete(w http.ResponseWriter, r *http.Request) {
+	userID, chirpID, ok := cfg.likeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.UnlikeChirp(r.Context(), database.UnlikeChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unlike chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) likeRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Could not get Token", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err == nil && !chirpVisibleTo(chirp, userID) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, chirpID, true
+}
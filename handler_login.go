@@ -22,6 +22,7 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		CreatedAt    time.Time `json:"created_at"`
 		UpdatedAt    time.Time `json:"updated_at"`
 		Email        string    `json:"email"`
+		Handle       string    `json:"handle"`
 		Token        string    `json:"token"`
 		RefreshToken string    `json:"refresh_token"`
 		IsChirpyRed  bool      `json:"is_chirpy_red"`
@@ -52,6 +53,15 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Logging in during the deletion grace period keeps the account.
+	if user.DeleteAfter.Valid {
+		_, err = cfg.db.CancelUserDeletion(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't cancel account deletion", err)
+			return
+		}
+	}
+
 	token, err := auth.MakeJWT(user.ID, cfg.secret)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Cannot make token", err)
@@ -82,6 +92,7 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 			CreatedAt:    user.CreatedAt,
 			UpdatedAt:    user.UpdatedAt,
 			Email:        user.Email,
+			Handle:       user.Handle.String,
 			Token:        token,
 			RefreshToken: refreshTokenString,
 			IsChirpyRed:  user.IsChirpyRed.Bool,
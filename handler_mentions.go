@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerMentionsGet(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	chirps, err := cfg.db.ListChirpsMentioningUser(r.Context(), database.ListChirpsMentioningUserParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get mentions", err)
+		return
+	}
+
+	chirps, nextCursor := paginate(chirps, page, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	listOfChirps, err := cfg.chirpsToResponse(r.Context(), userID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get mentions", err)
+		return
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     listOfChirps,
+		NextCursor: nextCursor,
+	})
+}
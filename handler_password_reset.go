@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+	chirpymail "github.com/seantesterman/chirpy/internal/mail"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	// passwordResetCooldown is how long after one reset email another
+	// request for the same account is ignored, unless the first was used.
+	passwordResetCooldown = 5 * time.Minute
+	// mailSendTimeout bounds a mail sent in the background after the
+	// response has gone out.
+	mailSendTimeout = 30 * time.Second
+)
+
+// handlerPasswordResetRequest always answers 202 straight away and does the
+// work afterwards, so neither the response nor how long it takes reveals
+// whether an account uses the address.
+func (cfg *apiConfig) handlerPasswordResetRequest(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Email string `json:"email"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), mailSendTimeout)
+	go func() {
+		defer cancel()
+		cfg.sendPasswordReset(ctx, strings.TrimSpace(params.Email))
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// sendPasswordReset mails a reset token to the account using email, if
+// there is one and it hasn't been sent one recently. Only a hash of the
+// token is stored.
+func (cfg *apiConfig) sendPasswordReset(ctx context.Context, email string) {
+	user, err := cfg.db.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error looking up user for password reset: %s", err)
+		return
+	}
+
+	recent, err := cfg.db.HasRecentPasswordReset(ctx, database.HasRecentPasswordResetParams{
+		UserID:        user.ID,
+		WindowSeconds: int32(passwordResetCooldown / time.Second),
+	})
+	if err != nil {
+		log.Printf("Error checking recent password resets for user %s: %s", user.ID, err)
+		return
+	}
+	if recent {
+		return
+	}
+
+	token, err := auth.MakeRefreshToken()
+	if err != nil {
+		log.Printf("Error making password reset token: %s", err)
+		return
+	}
+
+	err = cfg.db.CreatePasswordReset(ctx, database.CreatePasswordResetParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(passwordResetTTL),
+	})
+	if err != nil {
+		log.Printf("Error saving password reset for user %s: %s", user.ID, err)
+		return
+	}
+
+	err = cfg.mailer.Send(ctx, chirpymail.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf(
+			"Use this code to choose a new password:\n\n%s\n\nIt expires in %d minutes. If you didn't ask to reset your password you can ignore this email.\n",
+			token, int(passwordResetTTL.Minutes()),
+		),
+	})
+	if err != nil {
+		log.Printf("Error sending password reset to user %s: %s", user.ID, err)
+	}
+}
+
+// handlerPasswordResetConfirm sets a new password from a reset token. It
+// also invalidates the user's other reset tokens and signs them out
+// everywhere by revoking their refresh tokens.
+func (cfg *apiConfig) handlerPasswordResetConfirm(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	if params.Password == "" {
+		respondWithError(w, http.StatusBadRequest, "Password can't be empty", nil)
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(params.Password)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	reset, err := qtx.UsePasswordReset(r.Context(), database.UsePasswordResetParams{
+		TokenHash: auth.HashToken(params.Token),
+		Now:       time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "Invalid or expired reset token", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+
+	_, err = qtx.PatchUser(r.Context(), database.PatchUserParams{
+		ID:             reset.UserID,
+		HashedPassword: sql.NullString{String: hashedPassword, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+
+	err = qtx.InvalidatePasswordResets(r.Context(), reset.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+
+	err = qtx.RevokeAllRefreshTokens(r.Context(), reset.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerPinCreate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		ChirpID uuid.UUID `json:"chirp_id"`
+	}
+
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), params.ChirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+
+	if userID != chirp.UserID {
+		respondWithError(w, http.StatusForbidden, "Incorrect author of Chirp", nil)
+		return
+	}
+
+	// Only chirps everyone can see can be pinned. A rechirp isn't the
+	// author's own chirp, so it can't be pinned either.
+	if !chirpPublic(chirp) {
+		respondWithError(w, http.StatusBadRequest, "Only public chirps can be pinned", nil)
+		return
+	}
+	if chirp.RechirpOf.Valid {
+		respondWithError(w, http.StatusBadRequest, "Rechirps can't be pinned", nil)
+		return
+	}
+
+	err = cfg.db.SetPinnedChirp(r.Context(), database.SetPinnedChirpParams{
+		ID:            userID,
+		PinnedChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't pin chirp", err)
+		return
+	}
+
+	response, err := cfg.chirpToResponse(r.Context(), userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+	response.Pinned = true
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *apiConfig) handlerPinDelete(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	err = cfg.db.SetPinnedChirp(r.Context(), database.SetPinnedChirpParams{
+		ID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unpin chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pinnedChirp returns the chirp a user has pinned, or nil if they have none
+// or the viewer can't see it. A pinned chirp that has since been deleted
+// counts as none.
+func (cfg *apiConfig) pinnedChirp(ctx context.Context, user database.User, viewerID uuid.UUID) (*database.Chirp, error) {
+	if !user.PinnedChirpID.Valid {
+		return nil, nil
+	}
+	chirp, err := cfg.db.GetChirp(ctx, user.PinnedChirpID.UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chirp.PublishAt.Valid || !chirpVisibleTo(chirp, viewerID) {
+		return nil, nil
+	}
+	return &chirp, nil
+}
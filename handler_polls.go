@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/chirptext"
+	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/moderation"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// Poll is embedded in the chirp that carries it. Vote counts are left out
+// until the viewer has voted or the poll has closed, so they can't sway
+// the vote.
+type Poll struct {
+	ID         uuid.UUID    `json:"id"`
+	ClosesAt   time.Time    `json:"closes_at"`
+	Closed     bool         `json:"closed"`
+	TotalVotes *int64       `json:"total_votes,omitempty"`
+	MyVote     *uuid.UUID   `json:"my_vote"`
+	Options    []PollOption `json:"options"`
+}
+
+type PollOption struct {
+	ID    uuid.UUID `json:"id"`
+	Label string    `json:"label"`
+	Votes *int64    `json:"votes,omitempty"`
+}
+
+type pollParameters struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+// validatePoll checks a poll for a chirp that goes public at opensAt and
+// returns the option labels to store. Labels go through the same filters as
+// the chirp body: a label that would be held holds the whole chirp, so the
+// returned verdict replaces the one for the body.
+func (cfg *apiConfig) validatePoll(params pollParameters, opensAt time.Time, verdict moderation.Verdict) ([]string, moderation.Verdict, error) {
+	if len(params.Options) < minPollOptions || len(params.Options) > maxPollOptions {
+		return nil, moderation.Verdict{}, errors.New("A poll needs between 2 and 4 options")
+	}
+	if params.ClosesAt.Before(opensAt.Add(minPollDuration)) {
+		return nil, moderation.Verdict{}, errors.New("Poll must stay open for at least 5 minutes")
+	}
+	if params.ClosesAt.After(opensAt.Add(maxPollDuration)) {
+		return nil, moderation.Verdict{}, errors.New("Poll can't stay open for more than 7 days")
+	}
+
+	labels := make([]string, 0, len(params.Options))
+	seen := map[string]bool{}
+	for _, option := range params.Options {
+		label := chirptext.Normalize(strings.TrimSpace(option))
+		if label == "" {
+			return nil, moderation.Verdict{}, errors.New("Poll options can't be empty")
+		}
+		if chirptext.Length(label) > maxPollOptionLength {
+			return nil, moderation.Verdict{}, errors.New("Poll option is too long")
+		}
+		if seen[strings.ToLower(label)] {
+			return nil, moderation.Verdict{}, errors.New("Poll options must be different")
+		}
+		seen[strings.ToLower(label)] = true
+
+		labelVerdict := cfg.chirpFilter.Check(label)
+		if labelVerdict.Action == moderation.Reject {
+			return nil, moderation.Verdict{}, errors.New("Poll option violates the content rules")
+		}
+		if labelVerdict.Action > verdict.Action {
+			verdict.Action = labelVerdict.Action
+		}
+		verdict.Reasons = append(verdict.Reasons, labelVerdict.Reasons...)
+		labels = append(labels, labelVerdict.Body)
+	}
+	return labels, verdict, nil
+}
+
+// insertPoll attaches a poll to a new chirp. q must be bound to the
+// transaction that created the chirp.
+func insertPoll(ctx context.Context, q *database.Queries, chirpID uuid.UUID, closesAt time.Time, labels []string) error {
+	poll, err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:  chirpID,
+		ClosesAt: closesAt.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	for i, label := range labels {
+		err = q.CreatePollOption(ctx, database.CreatePollOptionParams{
+			PollID:   poll.ID,
+			Position: int32(i),
+			Label:    label,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pollsForChirps loads the polls on a batch of chirps, keyed by chirp ID,
+// with the tallies filled in only where the viewer may see them.
+func (cfg *apiConfig) pollsForChirps(ctx context.Context, viewerID uuid.UUID, chirpIDs []uuid.UUID) (map[uuid.UUID]*Poll, error) {
+	results, err := cfg.db.GetPollResults(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return map[uuid.UUID]*Poll{}, nil
+	}
+
+	voteByChirpID := map[uuid.UUID]uuid.UUID{}
+	if viewerID != uuid.Nil {
+		votes, err := cfg.db.GetPollVotesByUser(ctx, database.GetPollVotesByUserParams{
+			UserID:   viewerID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, vote := range votes {
+			voteByChirpID[vote.ChirpID] = vote.OptionID
+		}
+	}
+
+	now := time.Now().UTC()
+	pollByChirpID := map[uuid.UUID]*Poll{}
+	totals := map[uuid.UUID]int64{}
+	for _, row := range results {
+		poll, ok := pollByChirpID[row.ChirpID]
+		if !ok {
+			poll = &Poll{
+				ID:       row.PollID,
+				ClosesAt: row.ClosesAt,
+				Closed:   !now.Before(row.ClosesAt),
+				Options:  []PollOption{},
+			}
+			if optionID, voted := voteByChirpID[row.ChirpID]; voted {
+				poll.MyVote = &optionID
+			}
+			pollByChirpID[row.ChirpID] = poll
+		}
+
+		option := PollOption{
+			ID:    row.OptionID,
+			Label: row.Label,
+		}
+		if poll.Closed || poll.MyVote != nil {
+			votes := row.VoteCount
+			option.Votes = &votes
+		}
+		poll.Options = append(poll.Options, option)
+		totals[row.ChirpID] += row.VoteCount
+	}
+
+	for chirpID, poll := range pollByChirpID {
+		if poll.Closed || poll.MyVote != nil {
+			total := totals[chirpID]
+			poll.TotalVotes = &total
+		}
+	}
+	return pollByChirpID, nil
+}
+
+func (cfg *apiConfig) handlerPollVote(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		OptionID uuid.UUID `json:"option_id"`
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Could not get Token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err == nil && (chirp.PublishAt.Valid || !chirpVisibleTo(chirp, userID)) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+
+	// Voting through a rechirp counts towards the original's poll, so the
+	// original has to be visible too.
+	if chirp.RechirpOf.Valid {
+		original, err := cfg.db.GetChirp(r.Context(), chirp.RechirpOf.UUID)
+		if err == nil && (original.PublishAt.Valid || original.DeletedAt.Valid || !chirpVisibleTo(original, userID)) {
+			err = sql.ErrNoRows
+		}
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+	}
+
+	poll, err := cfg.db.GetPollByChirpID(r.Context(), originalChirpID(chirp))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp has no poll", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get poll", err)
+		return
+	}
+
+	if !time.Now().UTC().Before(poll.ClosesAt) {
+		respondWithError(w, http.StatusConflict, "Poll is closed", nil)
+		return
+	}
+
+	_, err = cfg.db.CastPollVote(r.Context(), database.CastPollVoteParams{
+		PollID:   poll.ID,
+		OptionID: params.OptionID,
+		UserID:   userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusConflict, "You already voted in this poll", err)
+		return
+	}
+	if isForeignKeyViolation(err) {
+		respondWithError(w, http.StatusBadRequest, "Option is not part of this poll", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record vote", err)
+		return
+	}
+
+	response, err := cfg.chirpToResponse(r.Context(), userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/chirptext"
+	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/moderation"
+)
+
+// Profile field limits, in user-perceived characters.
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+	maxLocationLength    = 30
+)
+
+// UserProfile is the public view of a user. It never includes the email.
+type UserProfile struct {
+	ID             uuid.UUID   `json:"id"`
+	CreatedAt      time.Time   `json:"created_at"`
+	Handle         string      `json:"handle"`
+	DisplayName    string      `json:"display_name"`
+	Bio            string      `json:"bio"`
+	Location       string      `json:"location"`
+	Avatar         *Attachment `json:"avatar"`
+	IsChirpyRed    bool        `json:"is_chirpy_red"`
+	FollowerCount  int64       `json:"follower_count"`
+	FollowingCount int64       `json:"following_count"`
+	ChirpCount     int64       `json:"chirp_count"`
+	PinnedChirp    *Chirp      `json:"pinned_chirp"`
+}
+
+// handlerUserProfile looks a user up by handle. A user ID is accepted too,
+// since chirps only carry the author's ID.
+func (cfg *apiConfig) handlerUserProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	handle := strings.TrimPrefix(vars["handle"], "@")
+
+	var user database.User
+	var err error
+	if userID, parseErr := uuid.Parse(handle); parseErr == nil {
+		user, err = cfg.db.GetUserByID(r.Context(), userID)
+	} else {
+		user, err = cfg.db.GetUserByHandle(r.Context(), handle)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+
+	profile, err := cfg.userProfile(r.Context(), user, cfg.viewerID(r))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get profile", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+// handlerUsersMeUpdate edits the caller's account and profile. Fields left
+// out of the request are unchanged; an empty string clears a profile field.
+// Changing the email or password also needs the current password, so a
+// leaked access token isn't enough to take over the account.
+func (cfg *apiConfig) handlerUsersMeUpdate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Email           *string `json:"email"`
+		Password        *string `json:"password"`
+		CurrentPassword string  `json:"current_password"`
+		Handle          *string `json:"handle"`
+		DisplayName     *string `json:"display_name"`
+		Bio             *string `json:"bio"`
+		Location        *string `json:"location"`
+		AvatarID        *string `json:"avatar_id"`
+	}
+
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	update := database.PatchUserParams{ID: userID}
+
+	if params.Email != nil || params.Password != nil {
+		if !cfg.checkCurrentPassword(w, r, userID, params.CurrentPassword) {
+			return
+		}
+	}
+	if params.Email != nil {
+		email, err := parseEmail(*params.Email)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		update.Email = sql.NullString{String: email, Valid: true}
+	}
+	if params.Password != nil {
+		if *params.Password == "" {
+			respondWithError(w, http.StatusBadRequest, "Password can't be empty", nil)
+			return
+		}
+		hashedPassword, err := auth.HashPassword(*params.Password)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update password", err)
+			return
+		}
+		update.HashedPassword = sql.NullString{String: hashedPassword, Valid: true}
+	}
+
+	if params.Handle != nil {
+		if *params.Handle == "" {
+			respondWithError(w, http.StatusBadRequest, "Handle can't be removed", nil)
+			return
+		}
+		update.Handle, err = parseHandle(*params.Handle)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	update.DisplayName, err = cfg.profileText(params.DisplayName, "Display name", maxDisplayNameLength, false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	update.Bio, err = cfg.profileText(params.Bio, "Bio", maxBioLength, true)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	update.Location, err = cfg.profileText(params.Location, "Location", maxLocationLength, false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if params.AvatarID != nil {
+		update.SetAvatar = true
+		if *params.AvatarID != "" {
+			avatarID, err := uuid.Parse(*params.AvatarID)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid avatar ID", err)
+				return
+			}
+			// The avatar must be an upload of the caller's that isn't
+			// already on a chirp, so deleting a chirp never takes it away.
+			_, err = cfg.db.GetUnattachedAttachment(r.Context(), database.GetUnattachedAttachmentParams{
+				ID:     avatarID,
+				UserID: userID,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusBadRequest, "Attachment not found or already used", err)
+				return
+			}
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Couldn't get attachment", err)
+				return
+			}
+			update.AvatarID = uuid.NullUUID{UUID: avatarID, Valid: true}
+		}
+	}
+
+	user, err := cfg.db.PatchUser(r.Context(), update)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Email or handle already taken", err)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update profile", err)
+		return
+	}
+
+	// A new address has to be verified again.
+	if update.Email.Valid && !user.EmailVerifiedAt.Valid {
+		cfg.sendVerificationEmailInBackground(r.Context(), user)
+	}
+
+	profile, err := cfg.userProfile(r.Context(), user, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get profile", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+// checkCurrentPassword confirms the caller knows their password before a
+// credential change or account deletion, writing the error response itself
+// on failure.
+func (cfg *apiConfig) checkCurrentPassword(w http.ResponseWriter, r *http.Request, userID uuid.UUID, password string) bool {
+	if password == "" {
+		respondWithError(w, http.StatusBadRequest, "current_password is required", nil)
+		return false
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return false
+	}
+
+	if !user.HashedPassword.Valid {
+		respondWithError(w, http.StatusForbidden, "Incorrect password", nil)
+		return false
+	}
+	err = auth.CheckPasswordHash(password, user.HashedPassword.String)
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, "Incorrect password", err)
+		return false
+	}
+	return true
+}
+
+// profileText validates an optional free-text profile field. Profile text
+// goes through the chirp filters, but nothing reviews profiles, so text
+// that would hold a chirp is refused outright.
+func (cfg *apiConfig) profileText(value *string, field string, maxLength int, multiline bool) (sql.NullString, error) {
+	if value == nil {
+		return sql.NullString{}, nil
+	}
+
+	text := chirptext.Normalize(strings.TrimSpace(*value))
+	for _, r := range text {
+		if unicode.IsControl(r) && !(multiline && r == '\n') {
+			return sql.NullString{}, fmt.Errorf("%s contains invalid characters", field)
+		}
+	}
+	if chirptext.Length(text) > maxLength {
+		return sql.NullString{}, fmt.Errorf("%s is too long", field)
+	}
+
+	verdict := cfg.chirpFilter.Check(text)
+	if verdict.Action >= moderation.Hold {
+		return sql.NullString{}, fmt.Errorf("%s violates the content rules", field)
+	}
+	return sql.NullString{String: verdict.Body, Valid: true}, nil
+}
+
+func (cfg *apiConfig) userProfile(ctx context.Context, user database.User, viewerID uuid.UUID) (UserProfile, error) {
+	profile := UserProfile{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		Handle:      user.Handle.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Location:    user.Location,
+		IsChirpyRed: user.IsChirpyRed.Bool,
+	}
+
+	if user.AvatarID.Valid {
+		avatar, err := cfg.db.GetAttachment(ctx, user.AvatarID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return UserProfile{}, err
+		}
+		if err == nil {
+			converted := cfg.databaseAttachmentToAttachment(avatar)
+			profile.Avatar = &converted
+		}
+	}
+
+	counts, err := cfg.db.GetUserProfileCounts(ctx, user.ID)
+	if err != nil {
+		return UserProfile{}, err
+	}
+	profile.FollowerCount = counts.FollowerCount
+	profile.FollowingCount = counts.FollowingCount
+	profile.ChirpCount = counts.ChirpCount
+
+	pinned, err := cfg.pinnedChirp(ctx, user, viewerID)
+	if err != nil {
+		return UserProfile{}, err
+	}
+	if pinned != nil {
+		response, err := cfg.chirpToResponse(ctx, viewerID, *pinned)
+		if err != nil {
+			return UserProfile{}, err
+		}
+		response.Pinned = true
+		profile.PinnedChirp = &response
+	}
+	return profile, nil
+}
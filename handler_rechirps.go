@@ -0,0 +1,121 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerRechirpCreate(w http.ResponseWriter, r *http.Request) {
+	userID, original, ok := cfg.rechirpRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if !chirpPublic(original) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+
+	if !cfg.requirePostingAllowed(w, r, userID) {
+		return
+	}
+
+	rechirp, err := cfg.db.CreateRechirp(r.Context(), database.CreateRechirpParams{
+		UserID:    userID,
+		RechirpOf: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusConflict, "Chirp already rechirped", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp chirp", err)
+		return
+	}
+
+	response, err := cfg.chirpToResponse(r.Context(), userID, rechirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+func (cfg *apiConfig) handlerRechirpDelete(w http.ResponseWriter, r *http.Request) {
+	userID, original, ok := cfg.rechirpRequest(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+		UserID:    userID,
+		RechirpOf: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Chirp not rechirped", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rechirpRequest authenticates the caller and resolves the chirp being
+// rechirped. Rechirping a rechirp targets the chirp it amplifies. It doesn't
+// check visibility, so a rechirp can still be undone after the original has
+// been hidden or held.
+func (cfg *apiConfig) rechirpRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, database.Chirp, bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Could not get Token", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	if chirp.RechirpOf.Valid {
+		chirp, err = cfg.db.GetChirp(r.Context(), chirp.RechirpOf.UUID)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return uuid.Nil, database.Chirp{}, false
+		}
+	}
+
+	return userID, chirp, true
+}
+
+// originalChirpID returns the chirp a rechirp amplifies, or the chirp
+// itself, so quotes and rechirps always point at real content.
+func originalChirpID(chirp database.Chirp) uuid.UUID {
+	if chirp.RechirpOf.Valid {
+		return chirp.RechirpOf.UUID
+	}
+	return chirp.ID
+}
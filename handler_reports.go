@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// reportReasons are the reason codes users can report a chirp for. Reports
+// raised by the moderation filters use "automated" instead.
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate":           true,
+	"violence":       true,
+	"sexual":         true,
+	"misinformation": true,
+	"other":          true,
+}
+
+const (
+	maxReportDetailsLength = 1000
+	maxSuspensionDays      = 365
+)
+
+type Report struct {
+	ID             uuid.UUID  `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ChirpID        *uuid.UUID `json:"chirp_id"`
+	ChirpAuthorID  uuid.UUID  `json:"chirp_author_id"`
+	ChirpBody      string     `json:"chirp_body"`
+	ReporterID     *uuid.UUID `json:"reporter_id"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status"`
+	Resolution     string     `json:"resolution,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ReportPage struct {
+	Reports    []Report `json:"reports"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+func databaseReportToReport(report database.Report) Report {
+	converted := Report{
+		ID:             report.ID,
+		CreatedAt:      report.CreatedAt,
+		ChirpAuthorID:  report.ChirpAuthorID,
+		ChirpBody:      report.ChirpBody,
+		Reason:         report.Reason,
+		Details:        report.Details,
+		Status:         report.Status,
+		Resolution:     report.Resolution.String,
+		ResolutionNote: report.ResolutionNote,
+	}
+	if report.ChirpID.Valid {
+		chirpID := report.ChirpID.UUID
+		converted.ChirpID = &chirpID
+	}
+	if report.ReporterID.Valid {
+		reporterID := report.ReporterID.UUID
+		converted.ReporterID = &reporterID
+	}
+	if report.ResolvedBy.Valid {
+		resolvedBy := report.ResolvedBy.UUID
+		converted.ResolvedBy = &resolvedBy
+	}
+	if report.ResolvedAt.Valid {
+		resolvedAt := report.ResolvedAt.Time
+		converted.ResolvedAt = &resolvedAt
+	}
+	return converted
+}
+
+// reportHeldChirp puts a chirp the moderation filters held into the review
+// queue, with the rules that fired as the details.
+func reportHeldChirp(ctx context.Context, q *database.Queries, chirp database.Chirp, reasons []string) error {
+	_, err := q.CreateReport(ctx, database.CreateReportParams{
+		ChirpID:       uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		ChirpAuthorID: chirp.UserID,
+		ChirpBody:     chirp.Body,
+		Reason:        "automated",
+		Details:       strings.Join(reasons, "; "),
+	})
+	return err
+}
+
+func (cfg *apiConfig) handlerReportCreate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Could not get Token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to validate token", err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	if !reportReasons[params.Reason] {
+		respondWithError(w, http.StatusBadRequest, "Invalid report reason", nil)
+		return
+	}
+	if len(params.Details) > maxReportDetailsLength {
+		respondWithError(w, http.StatusBadRequest, "Report details are too long", nil)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err == nil && !chirpVisibleTo(chirp, userID) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+
+	if chirp.UserID == userID {
+		respondWithError(w, http.StatusBadRequest, "You can't report your own chirp", nil)
+		return
+	}
+
+	report, err := cfg.db.CreateReport(r.Context(), database.CreateReportParams{
+		ChirpID:       uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		ReporterID:    uuid.NullUUID{UUID: userID, Valid: true},
+		ChirpAuthorID: chirp.UserID,
+		ChirpBody:     chirp.Body,
+		Reason:        params.Reason,
+		Details:       params.Details,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusConflict, "You already reported this chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseReportToReport(report))
+}
+
+func (cfg *apiConfig) handlerReportsGet(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "open"
+	}
+	if status != "open" && status != "closed" {
+		respondWithError(w, http.StatusBadRequest, "Status must be open or closed", nil)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	reports, err := cfg.db.ListReports(r.Context(), database.ListReportsParams{
+		Status:          status,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get reports", err)
+		return
+	}
+
+	reports, nextCursor := paginate(reports, page, func(report database.Report) (time.Time, uuid.UUID) {
+		return report.CreatedAt, report.ID
+	})
+
+	listOfReports := []Report{}
+	for _, report := range reports {
+		listOfReports = append(listOfReports, databaseReportToReport(report))
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ReportPage{
+		Reports:    listOfReports,
+		NextCursor: nextCursor,
+	})
+}
+
+// handlerReportResolve records a moderator's decision on a report and
+// carries it out. The decision closes every open report on the same chirp,
+// so a chirp reported many times is only reviewed once. Dismissing a report
+// on a chirp the filters held releases it, and a warning is recorded for the
+// author to see under GET /api/users/me/warnings.
+func (cfg *apiConfig) handlerReportResolve(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Action      string `json:"action"`
+		Note        string `json:"note"`
+		SuspendDays int    `json:"suspend_days"`
+	}
+
+	adminID, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	reportID, err := uuid.Parse(vars["reportID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid report ID", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	switch params.Action {
+	case "dismiss", "hide", "delete", "warn":
+	case "suspend":
+		if params.SuspendDays < 1 || params.SuspendDays > maxSuspensionDays {
+			respondWithError(w, http.StatusBadRequest, "suspend_days must be between 1 and 365", nil)
+			return
+		}
+	default:
+		respondWithError(w, http.StatusBadRequest, "Action must be one of dismiss, hide, delete, warn or suspend", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.GetReportForUpdate(r.Context(), reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Report not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get report", err)
+		return
+	}
+	if report.Status != "open" {
+		respondWithError(w, http.StatusConflict, "Report is already resolved", nil)
+		return
+	}
+
+	resolved, err := qtx.ResolveReports(r.Context(), database.ResolveReportsParams{
+		Resolution:     sql.NullString{String: params.Action, Valid: true},
+		ResolutionNote: params.Note,
+		ResolvedBy:     uuid.NullUUID{UUID: adminID, Valid: true},
+		ID:             report.ID,
+		ChirpID:        report.ChirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve report", err)
+		return
+	}
+
+	switch params.Action {
+	case "dismiss":
+		if report.ChirpID.Valid {
+			err = releaseHeldChirp(r.Context(), qtx, report.ChirpID.UUID)
+		}
+	case "hide":
+		if report.ChirpID.Valid {
+			err = qtx.SetChirpModerationStatus(r.Context(), database.SetChirpModerationStatusParams{
+				ID:               report.ChirpID.UUID,
+				ModerationStatus: chirpStatusHidden,
+			})
+		}
+	case "delete":
+		// Hiding it as well stops the author restoring it from the trash.
+		if report.ChirpID.Valid {
+			err = qtx.SetChirpModerationStatus(r.Context(), database.SetChirpModerationStatusParams{
+				ID:               report.ChirpID.UUID,
+				ModerationStatus: chirpStatusHidden,
+			})
+			if err == nil {
+				err = qtx.DeleteChirp(r.Context(), database.DeleteChirpParams{
+					ID:        report.ChirpID.UUID,
+					DeletedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+				})
+			}
+		}
+	case "warn":
+		_, err = qtx.CreateWarning(r.Context(), database.CreateWarningParams{
+			UserID:    report.ChirpAuthorID,
+			ReportID:  uuid.NullUUID{UUID: report.ID, Valid: true},
+			ChirpID:   report.ChirpID,
+			ChirpBody: report.ChirpBody,
+			Reason:    report.Reason,
+			Note:      params.Note,
+		})
+	case "suspend":
+		err = qtx.SuspendUser(r.Context(), database.SuspendUserParams{
+			ID: report.ChirpAuthorID,
+			SuspendedUntil: sql.NullTime{
+				Time:  time.Now().UTC().Add(time.Duration(params.SuspendDays) * 24 * time.Hour),
+				Valid: true,
+			},
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't apply moderation action", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve report", err)
+		return
+	}
+
+	for _, closed := range resolved {
+		if closed.ID == report.ID {
+			respondWithJSON(w, http.StatusOK, databaseReportToReport(closed))
+			return
+		}
+	}
+	respondWithError(w, http.StatusInternalServerError, "Couldn't resolve report", nil)
+}
+
+// releaseHeldChirp makes a chirp the filters held visible. Chirps hidden by
+// a moderator stay hidden and deleted chirps are left alone.
+func releaseHeldChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID) error {
+	chirp, err := q.GetChirpForUpdate(ctx, chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if chirp.ModerationStatus != chirpStatusHeld {
+		return nil
+	}
+	return q.SetChirpModerationStatus(ctx, database.SetChirpModerationStatusParams{
+		ID:               chirpID,
+		ModerationStatus: chirpStatusVisible,
+	})
+}
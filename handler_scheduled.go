@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// maxScheduleAhead is how far in the future a chirp can be scheduled.
+const maxScheduleAhead = 365 * 24 * time.Hour
+
+// handlerScheduledGet lists the caller's chirps that are waiting to be
+// published, soonest first.
+func (cfg *apiConfig) handlerScheduledGet(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	chirps, err := cfg.db.ListScheduledChirps(r.Context(), database.ListScheduledChirpsParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get scheduled chirps", err)
+		return
+	}
+
+	// The queue is ordered by publish time, so that is what the cursor
+	// holds.
+	chirps, nextCursor := paginate(chirps, page, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.PublishAt.Time, chirp.ID
+	})
+
+	listOfChirps, err := cfg.chirpsToResponse(r.Context(), userID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get scheduled chirps", err)
+		return
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     listOfChirps,
+		NextCursor: nextCursor,
+	})
+}
+
+// handlerScheduledDelete cancels a scheduled chirp. It was never public, so
+// it is removed outright rather than going to the trash.
+func (cfg *apiConfig) handlerScheduledDelete(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	chirpID, err := uuid.Parse(vars["chirpID"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	attachments, err := cfg.db.GetAttachmentsForChirps(r.Context(), []uuid.UUID{chirpID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get attachments", err)
+		return
+	}
+
+	deleted, err := cfg.db.DeleteScheduledChirp(r.Context(), database.DeleteScheduledChirpParams{
+		ID:     chirpID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete scheduled chirp", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found", nil)
+		return
+	}
+
+	for _, attachment := range attachments {
+		cfg.deleteAttachmentBlobs(r.Context(), attachment)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,12 +3,14 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/chirptext"
 	"github.com/seantesterman/chirpy/internal/database"
 )
 
@@ -24,13 +26,16 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 	type parameters struct {
 		Password string `json:"password"`
 		Email    string `json:"email"`
+		Handle   string `json:"handle"`
 	}
 	type UserResponse struct {
-		ID          uuid.UUID `json:"id"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
-		Email       string    `json:"email"`
-		IsChirpyRed bool      `json:"is_chirpy_red"`
+		ID            uuid.UUID `json:"id"`
+		CreatedAt     time.Time `json:"created_at"`
+		UpdatedAt     time.Time `json:"updated_at"`
+		Email         string    `json:"email"`
+		Handle        string    `json:"handle"`
+		IsChirpyRed   bool      `json:"is_chirpy_red"`
+		EmailVerified bool      `json:"email_verified"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -41,6 +46,18 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	email, err := parseEmail(params.Email)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	handle, err := parseHandle(params.Handle)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
 	HashedPW, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create password", err)
@@ -48,42 +65,56 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 	}
 
 	user_params := database.CreateUserParams{
-		Email: params.Email,
+		Email: email,
 		HashedPassword: sql.NullString{
 			String: HashedPW,
 			Valid:  true,
 		},
+		Handle: handle,
 	}
 	user, err := cfg.db.CreateUser(r.Context(), user_params)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Email or handle already taken", err)
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create user", err)
 		return
 	}
 
+	cfg.sendVerificationEmailInBackground(r.Context(), user)
+
 	respondWithJSON(w, http.StatusCreated, UserResponse{
-		ID:          user.ID,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed.Bool,
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		Handle:        user.Handle.String,
+		IsChirpyRed:   user.IsChirpyRed.Bool,
+		EmailVerified: user.EmailVerifiedAt.Valid,
 	})
 }
 
+// handlerUsersUpdate is the original update endpoint, kept for existing
+// clients. Empty fields are left unchanged. Like PATCH /api/users/me,
+// changing the email or password needs the current password.
 func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, r *http.Request) {
 	type UserRequest struct {
-		Password string `json:"password"`
-		Email    string `json:"email"`
+		Password        string `json:"password"`
+		Email           string `json:"email"`
+		Handle          string `json:"handle"`
+		CurrentPassword string `json:"current_password"`
 	}
 	type UserResponse struct {
 		ID          uuid.UUID `json:"id"`
 		CreatedAt   time.Time `json:"created_at"`
 		UpdatedAt   time.Time `json:"updated_at"`
 		Email       string    `json:"email"`
+		Handle      string    `json:"handle"`
 		IsChirpyRed bool      `json:"is_chirpy_red"`
 	}
 
 	bearerToken, err := auth.GetBearerToken(r.Header)
-	fmt.Println(bearerToken)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
 		return
@@ -103,34 +134,75 @@ func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	hashedPW, err := auth.HashPassword(userRequest.Password)
+	if userRequest.Email != "" || userRequest.Password != "" {
+		if !cfg.checkCurrentPassword(w, r, userID, userRequest.CurrentPassword) {
+			return
+		}
+	}
+
+	handle, err := parseHandle(userRequest.Handle)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't update password", err)
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
 		return
 	}
 
-	updateStruct := database.UpdateUserParams{
-		Email: userRequest.Email,
-		HashedPassword: sql.NullString{
-			String: hashedPW,
-			Valid:  true,
-		},
-		ID: userID,
+	updateStruct := database.PatchUserParams{
+		Handle: handle,
+		ID:     userID,
+	}
+	if userRequest.Email != "" {
+		email, err := parseEmail(userRequest.Email)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		updateStruct.Email = sql.NullString{String: email, Valid: true}
+	}
+	if userRequest.Password != "" {
+		hashedPW, err := auth.HashPassword(userRequest.Password)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't update password", err)
+			return
+		}
+		updateStruct.HashedPassword = sql.NullString{String: hashedPW, Valid: true}
+	}
+	user, err := cfg.db.PatchUser(r.Context(), updateStruct)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Email or handle already taken", err)
+		return
 	}
-	user, err := cfg.db.UpdateUser(r.Context(), updateStruct)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't update email and password", err)
 		return
 	}
 
-	user.UpdatedAt = time.Now().UTC()
+	if updateStruct.Email.Valid && !user.EmailVerifiedAt.Valid {
+		cfg.sendVerificationEmailInBackground(r.Context(), user)
+	}
 
 	respondWithJSON(w, http.StatusOK, UserResponse{
 		ID:          user.ID,
 		CreatedAt:   user.CreatedAt,
 		UpdatedAt:   user.UpdatedAt,
-		Email:       userRequest.Email,
+		Email:       user.Email,
+		Handle:      user.Handle.String,
 		IsChirpyRed: user.IsChirpyRed.Bool,
 	})
 
 }
+
+// parseHandle validates an optional handle from a request body. An empty
+// handle means "not supplied" and maps to NULL.
+func parseHandle(handle string) (sql.NullString, error) {
+	if handle == "" {
+		return sql.NullString{}, nil
+	}
+	handle = strings.TrimPrefix(handle, "@")
+	if !chirptext.ValidHandle(handle) {
+		return sql.NullString{}, errors.New("Handle must be 1-15 letters, digits or underscores")
+	}
+	if chirptext.ReservedHandle(handle) {
+		return sql.NullString{}, errors.New("Handle is reserved")
+	}
+	return sql.NullString{String: handle, Valid: true}, nil
+}
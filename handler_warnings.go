@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/auth"
+	"github.com/seantesterman/chirpy/internal/database"
+)
+
+// Warning is a moderator's warning as its recipient sees it. The reporter
+// and the moderator who issued it are left out.
+type Warning struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ChirpID   *uuid.UUID `json:"chirp_id"`
+	ChirpBody string     `json:"chirp_body"`
+	Reason    string     `json:"reason"`
+	Note      string     `json:"note,omitempty"`
+}
+
+type WarningPage struct {
+	Warnings   []Warning `json:"warnings"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+func databaseWarningToWarning(warning database.Warning) Warning {
+	converted := Warning{
+		ID:        warning.ID,
+		CreatedAt: warning.CreatedAt,
+		ChirpBody: warning.ChirpBody,
+		Reason:    warning.Reason,
+		Note:      warning.Note,
+	}
+	if warning.ChirpID.Valid {
+		chirpID := warning.ChirpID.UUID
+		converted.ChirpID = &chirpID
+	}
+	return converted
+}
+
+// handlerWarningsGet lists the warnings moderators have given the caller,
+// newest first.
+func (cfg *apiConfig) handlerWarningsGet(w http.ResponseWriter, r *http.Request) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Token not found", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	warnings, err := cfg.db.ListWarnings(r.Context(), database.ListWarningsParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt(),
+		CursorID:        page.cursorID(),
+		PageLimit:       page.fetchLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get warnings", err)
+		return
+	}
+
+	warnings, nextCursor := paginate(warnings, page, func(warning database.Warning) (time.Time, uuid.UUID) {
+		return warning.CreatedAt, warning.ID
+	})
+
+	listOfWarnings := []Warning{}
+	for _, warning := range warnings {
+		listOfWarnings = append(listOfWarnings, databaseWarningToWarning(warning))
+	}
+
+	setNextLink(w, r, nextCursor)
+	respondWithJSON(w, http.StatusOK, WarningPage{
+		Warnings:   listOfWarnings,
+		NextCursor: nextCursor,
+	})
+}
@@ -154,3 +154,90 @@ func TestGetBearerToken(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSignedToken(t *testing.T) {
+	id := uuid.New()
+	validToken := MakeSignedToken(id, "verify:sean@example.com", "secret")
+
+	tests := []struct {
+		name        string
+		token       string
+		payload     string
+		tokenSecret string
+		wantErr     bool
+	}{
+		{
+			name:        "Valid token",
+			token:       validToken,
+			payload:     "verify:sean@example.com",
+			tokenSecret: "secret",
+			wantErr:     false,
+		},
+		{
+			name:        "Payload changed",
+			token:       validToken,
+			payload:     "verify:other@example.com",
+			tokenSecret: "secret",
+			wantErr:     true,
+		},
+		{
+			name:        "Wrong secret",
+			token:       validToken,
+			payload:     "verify:sean@example.com",
+			tokenSecret: "wrong_secret",
+			wantErr:     true,
+		},
+		{
+			name:        "Signature moved to another id",
+			token:       uuid.New().String() + validToken[36:],
+			payload:     "verify:sean@example.com",
+			tokenSecret: "secret",
+			wantErr:     true,
+		},
+		{
+			name:        "Malformed token",
+			token:       "not-a-token",
+			payload:     "verify:sean@example.com",
+			tokenSecret: "secret",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSignedToken(tt.token, tt.payload, tt.tokenSecret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSignedToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSignedToken(t *testing.T) {
+	id := uuid.New()
+	gotID, err := ParseSignedToken(MakeSignedToken(id, "payload", "secret"))
+	if err != nil {
+		t.Fatalf("ParseSignedToken() error = %v", err)
+	}
+	if gotID != id {
+		t.Errorf("ParseSignedToken() = %v, want %v", gotID, id)
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	token, err := MakeRefreshToken()
+	if err != nil {
+		t.Fatalf("MakeRefreshToken() error = %v", err)
+	}
+
+	if HashToken(token) != HashToken(token) {
+		t.Errorf("HashToken() is not deterministic")
+	}
+	if HashToken(token) == token {
+		t.Errorf("HashToken() returned the token unchanged")
+	}
+	other, _ := MakeRefreshToken()
+	if HashToken(token) == HashToken(other) {
+		t.Errorf("HashToken() gave two tokens the same hash")
+	}
+}
@@ -0,0 +1,14 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken returns the SHA-256 of a random token as hex, for storing
+// tokens that are only ever looked up, never shown again. Unlike
+// passwords, random tokens have enough entropy that a fast hash is safe.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
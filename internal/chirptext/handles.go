@@ -0,0 +1,31 @@
+package chirptext
+
+import "strings"
+
+// reservedHandles can't be claimed by users, either because they would
+// collide with routes such as /api/users/me or because they could be used
+// to impersonate the service.
+var reservedHandles = map[string]bool{
+	"admin":         true,
+	"administrator": true,
+	"api":           true,
+	"app":           true,
+	"chirpy":        true,
+	"help":          true,
+	"me":            true,
+	"mod":           true,
+	"moderator":     true,
+	"null":          true,
+	"root":          true,
+	"security":      true,
+	"settings":      true,
+	"staff":         true,
+	"support":       true,
+	"system":        true,
+}
+
+// ReservedHandle reports whether handle is reserved. Handles are compared
+// case-insensitively, the same way the database enforces uniqueness.
+func ReservedHandle(handle string) bool {
+	return reservedHandles[strings.ToLower(handle)]
+}
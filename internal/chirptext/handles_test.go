@@ -0,0 +1,41 @@
+package chirptext
+
+import "testing"
+
+func TestReservedHandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle string
+		want   bool
+	}{
+		{
+			name:   "Reserved",
+			handle: "admin",
+			want:   true,
+		},
+		{
+			name:   "Reserved in another case",
+			handle: "Me",
+			want:   true,
+		},
+		{
+			name:   "Reserved word as a prefix is allowed",
+			handle: "admiral",
+			want:   false,
+		},
+		{
+			name:   "Ordinary handle",
+			handle: "sean",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ReservedHandle(tt.handle)
+			if got != tt.want {
+				t.Errorf("ReservedHandle(%q) = %v, want %v", tt.handle, got, tt.want)
+			}
+		})
+	}
+}
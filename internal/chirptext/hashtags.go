@@ -0,0 +1,68 @@
+package chirptext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxHashtagLength = 100
+
+// ExtractHashtags returns the distinct hashtags in body, lowercased and
+// without the leading #, in the order they first appear. A hashtag starts
+// at a # that isn't part of a word, runs over letters, digits and
+// underscores, and must contain at least one letter.
+func ExtractHashtags(body string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, entity := range scanEntities(body, '#') {
+		tag := strings.ToLower(entity.Text)
+		if seen[tag] || !strings.ContainsFunc(tag, unicode.IsLetter) || utf8.RuneCountInString(tag) > maxHashtagLength {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NormalizeHashtag turns user input such as "#Go" into the stored form "go".
+func NormalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+// entity is a #tag or @handle found in a body. Start and End are rune
+// offsets of the whole entity including its sigil, End exclusive.
+type entity struct {
+	Text  string
+	Start int
+	End   int
+}
+
+func scanEntities(body string, sigil rune) []entity {
+	entities := []entity{}
+	runes := []rune(body)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != sigil || (i > 0 && isEntityRune(runes[i-1])) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && isEntityRune(runes[end]) {
+			end++
+		}
+		if end == i+1 {
+			continue
+		}
+		entities = append(entities, entity{
+			Text:  string(runes[i+1 : end]),
+			Start: i,
+			End:   end,
+		})
+		i = end - 1
+	}
+	return entities
+}
+
+func isEntityRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,59 @@
+package chirptext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "No hashtags",
+			body: "just a chirp",
+			want: []string{},
+		},
+		{
+			name: "Tags are lowercased and deduplicated",
+			body: "#Go is great, #go #GO",
+			want: []string{"go"},
+		},
+		{
+			name: "Punctuation ends a tag",
+			body: "loving #chirpy! and #boot_dev.",
+			want: []string{"chirpy", "boot_dev"},
+		},
+		{
+			name: "Unicode letters",
+			body: "#café time",
+			want: []string{"café"},
+		},
+		{
+			name: "Needs a letter",
+			body: "we're #1 #2024",
+			want: []string{},
+		},
+		{
+			name: "Not inside a word",
+			body: "issue#12 and foo#bar",
+			want: []string{},
+		},
+		{
+			name: "Bare sigil",
+			body: "# alone",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractHashtags(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractHashtags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package chirptext
+
+import (
+	"regexp"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// URLWeight is how many characters a link counts for, whatever its real
+// length, so shortened and full links cost the same.
+const URLWeight = 23
+
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s]+`)
+
+// Normalize puts body in Unicode NFC so that the same text is always stored
+// and measured the same way, however the client composed it.
+func Normalize(body string) string {
+	return norm.NFC.String(body)
+}
+
+// Length counts body the way a reader would: one per user-perceived
+// character (grapheme cluster), so an emoji with modifiers or a letter with
+// combining accents counts once, and URLWeight per http(s) link.
+func Length(body string) int {
+	length := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(body, -1) {
+		length += uniseg.GraphemeClusterCount(body[last:loc[0]]) + URLWeight
+		last = loc[1]
+	}
+	return length + uniseg.GraphemeClusterCount(body[last:])
+}
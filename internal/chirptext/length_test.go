@@ -0,0 +1,62 @@
+package chirptext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{
+			name: "ASCII",
+			body: "hello world",
+			want: 11,
+		},
+		{
+			name: "Emoji count once each",
+			body: strings.Repeat("😀", 50),
+			want: 50,
+		},
+		{
+			name: "Skin tone and ZWJ sequences are one character",
+			body: "👍🏽👨‍👩‍👧",
+			want: 2,
+		},
+		{
+			name: "Combining accent",
+			body: "cafe\u0301",
+			want: 4,
+		},
+		{
+			name: "URL has a fixed weight",
+			body: "see https://example.com/a/very/long/path/that/goes/on?and=on now",
+			want: 4 + URLWeight + 4,
+		},
+		{
+			name: "Empty",
+			body: "",
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Length(tt.body)
+			if got != tt.want {
+				t.Errorf("Length() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize("cafe\u0301")
+	want := "caf\u00e9"
+	if got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+}
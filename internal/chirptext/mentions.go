@@ -0,0 +1,37 @@
+package chirptext
+
+import "regexp"
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+
+// Mention is an @handle found in a chirp body. Start and End are offsets in
+// Unicode code points covering the @ and the handle, End exclusive.
+type Mention struct {
+	Handle string
+	Start  int
+	End    int
+}
+
+// ValidHandle reports whether handle has the shape of a user handle: 1 to
+// 15 ASCII letters, digits or underscores.
+func ValidHandle(handle string) bool {
+	return handlePattern.MatchString(handle)
+}
+
+// ExtractMentions returns every @handle in body in order of appearance.
+// Candidates that can't be handles, such as the domain of an email
+// address, are skipped. Resolving handles to users is left to the caller.
+func ExtractMentions(body string) []Mention {
+	mentions := []Mention{}
+	for _, entity := range scanEntities(body, '@') {
+		if !ValidHandle(entity.Text) {
+			continue
+		}
+		mentions = append(mentions, Mention{
+			Handle: entity.Text,
+			Start:  entity.Start,
+			End:    entity.End,
+		})
+	}
+	return mentions
+}
@@ -0,0 +1,55 @@
+package chirptext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Mention
+	}{
+		{
+			name: "No mentions",
+			body: "hello world",
+			want: []Mention{},
+		},
+		{
+			name: "Offsets are in code points",
+			body: "héllo @Sean and @boots!",
+			want: []Mention{
+				{Handle: "Sean", Start: 6, End: 11},
+				{Handle: "boots", Start: 16, End: 22},
+			},
+		},
+		{
+			name: "Repeated mentions are all returned",
+			body: "@a @a",
+			want: []Mention{
+				{Handle: "a", Start: 0, End: 2},
+				{Handle: "a", Start: 3, End: 5},
+			},
+		},
+		{
+			name: "Email addresses are not mentions",
+			body: "mail sean@example.com",
+			want: []Mention{},
+		},
+		{
+			name: "Too long to be a handle",
+			body: "@abcdefghijklmnopqrstuvwxyz",
+			want: []Mention{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractMentions(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractMentions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
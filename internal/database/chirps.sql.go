@@ -7,29 +7,46 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, quote_of, moderation_status, publish_at)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body             string
+	UserID           uuid.UUID
+	InReplyTo        uuid.NullUUID
+	QuoteOf          uuid.NullUUID
+	ModerationStatus string
+	PublishAt        sql.NullTime
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.InReplyTo,
+		arg.QuoteOf,
+		arg.ModerationStatus,
+		arg.PublishAt,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,23 +54,110 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.ModerationStatus,
+		&i.DeletedAt,
+		&i.PublishAt,
+	)
+	return i, err
+}
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    '',
+    $1,
+    $2
+)
+ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at
+`
+
+type CreateRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.NullUUID
+}
+
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp, arg.UserID, arg.RechirpOf)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.ModerationStatus,
+		&i.DeletedAt,
+		&i.PublishAt,
 	)
 	return i, err
 }
 
 const deleteChirp = `-- name: DeleteChirp :exec
-DELETE FROM chirps
-WHERE id = $1
+UPDATE chirps SET deleted_at = $2
+WHERE (id = $1 OR rechirp_of = $1) AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, id)
+type DeleteChirpParams struct {
+	ID        uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) DeleteChirp(ctx context.Context, arg DeleteChirpParams) error {
+	_, err := q.db.ExecContext(ctx, deleteChirp, arg.ID, arg.DeletedAt)
 	return err
 }
 
+const deleteRechirp = `-- name: DeleteRechirp :execrows
+DELETE FROM chirps
+WHERE user_id = $1 AND rechirp_of = $2 AND deleted_at IS NULL
+`
+
+type DeleteRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.NullUUID
+}
+
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRechirp, arg.UserID, arg.RechirpOf)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteScheduledChirp = `-- name: DeleteScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND publish_at IS NOT NULL
+`
+
+type DeleteScheduledChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteScheduledChirp(ctx context.Context, arg DeleteScheduledChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteScheduledChirp, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -65,17 +169,42 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.ModerationStatus,
+		&i.DeletedAt,
+		&i.PublishAt,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+const getChirpAncestors = `-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT parent.id, parent.in_reply_to, 1::int AS depth
+    FROM chirps child
+    JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE child.id = $1
+    UNION ALL
+    SELECT c.id, c.in_reply_to, ancestors.depth + 1
+    FROM chirps c
+    JOIN ancestors ON c.id = ancestors.in_reply_to
+    WHERE ancestors.depth < $2::int
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.rechirp_of, chirps.quote_of, chirps.moderation_status, chirps.deleted_at, chirps.publish_at FROM chirps
+JOIN ancestors ON chirps.id = ancestors.id
+WHERE chirps.deleted_at IS NULL
+ORDER BY ancestors.depth DESC
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpAncestorsParams struct {
+	ChirpID  uuid.UUID
+	MaxDepth int32
+}
+
+func (q *Queries) GetChirpAncestors(ctx context.Context, arg GetChirpAncestorsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAncestors, arg.ChirpID, arg.MaxDepth)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +218,13 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
 		); err != nil {
 			return nil, err
 		}
@@ -103,14 +239,402 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getChirpsByUser = `-- name: GetChirpsByUser :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.ModerationStatus,
+		&i.DeletedAt,
+		&i.PublishAt,
+	)
+	return i, err
+}
+
+const getChirpReplyTree = `-- name: GetChirpReplyTree :many
+WITH RECURSIVE tree AS (
+    SELECT page.id, 1::int AS depth
+    FROM (
+        SELECT c.id FROM chirps c
+        WHERE c.in_reply_to = $1
+        AND c.deleted_at IS NULL AND c.publish_at IS NULL
+        AND (c.moderation_status = 'visible' OR c.user_id = $2)
+        AND (
+            $3::timestamp IS NULL
+            OR (c.created_at, c.id) > ($3::timestamp, $4::uuid)
+        )
+        ORDER BY c.created_at ASC, c.id ASC
+        LIMIT $5
+    ) page
+    UNION ALL
+    SELECT c.id, tree.depth + 1
+    FROM chirps c
+    JOIN tree ON c.in_reply_to = tree.id
+    WHERE tree.depth < $6::int
+    AND c.deleted_at IS NULL AND c.publish_at IS NULL
+    AND (c.moderation_status = 'visible' OR c.user_id = $2)
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.rechirp_of, chirps.quote_of, chirps.moderation_status, chirps.deleted_at, chirps.publish_at, tree.depth
+FROM chirps
+JOIN tree ON chirps.id = tree.id
+ORDER BY tree.depth ASC, chirps.created_at ASC, chirps.id ASC
+LIMIT $7
+`
+
+type GetChirpReplyTreeParams struct {
+	ChirpID         uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+	MaxDepth        int32
+	MaxReplies      int32
+}
+
+type GetChirpReplyTreeRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) GetChirpReplyTree(ctx context.Context, arg GetChirpReplyTreeParams) ([]GetChirpReplyTreeRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpReplyTree,
+		arg.ChirpID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+		arg.MaxDepth,
+		arg.MaxReplies,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpReplyTreeRow
+	for rows.Next() {
+		var i GetChirpReplyTreeRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.ModerationStatus,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.PublishAt,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedChirp = `-- name: GetDeletedChirp :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) GetDeletedChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.ModerationStatus,
+		&i.DeletedAt,
+		&i.PublishAt,
+	)
+	return i, err
+}
+
+const getPurgeableAttachments = `-- name: GetPurgeableAttachments :many
+SELECT attachments.id, attachments.created_at, attachments.user_id, attachments.chirp_id, attachments.position, attachments.content_type, attachments.size_bytes, attachments.width, attachments.height, attachments.blob_key, attachments.thumbnail_key FROM attachments
+JOIN chirps ON chirps.id = attachments.chirp_id
+WHERE chirps.deleted_at < $1
+`
+
+func (q *Queries) GetPurgeableAttachments(ctx context.Context, deletedAt sql.NullTime) ([]Attachment, error) {
+	rows, err := q.db.QueryContext(ctx, getPurgeableAttachments, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ChirpID,
+			&i.Position,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.BlobKey,
+			&i.ThumbnailKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRechirpCounts = `-- name: GetRechirpCounts :many
+SELECT rechirp_of::uuid AS chirp_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of = ANY($1::uuid[]) AND deleted_at IS NULL
+GROUP BY rechirp_of
+`
+
+type GetRechirpCountsRow struct {
+	ChirpID      uuid.UUID
+	RechirpCount int64
+}
+
+func (q *Queries) GetRechirpCounts(ctx context.Context, chirpIds []uuid.UUID) ([]GetRechirpCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRechirpCounts, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRechirpCountsRow
+	for rows.Next() {
+		var i GetRechirpCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.RechirpCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReplyCounts = `-- name: GetReplyCounts :many
+SELECT in_reply_to::uuid AS chirp_id, COUNT(*) AS reply_count
+FROM chirps
+WHERE in_reply_to = ANY($1::uuid[]) AND deleted_at IS NULL AND publish_at IS NULL
+AND moderation_status = 'visible'
+GROUP BY in_reply_to
+`
+
+type GetReplyCountsRow struct {
+	ChirpID    uuid.UUID
+	ReplyCount int64
+}
+
+func (q *Queries) GetReplyCounts(ctx context.Context, chirpIds []uuid.UUID) ([]GetReplyCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReplyCounts, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReplyCountsRow
+	for rows.Next() {
+		var i GetReplyCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimeline = `-- name: GetTimeline :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND (
+    user_id = $1
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1)
+)
+AND (moderation_status = 'visible' OR user_id = $1)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetTimelineParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) GetTimeline(ctx context.Context, arg GetTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTimeline,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirps = `-- name: ListChirps :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND ($1::uuid IS NULL OR user_id = $1)
+AND (moderation_status = 'visible' OR user_id = $2)
+AND ($3::uuid IS NULL OR id <> $3)
+AND (
+    $4::timestamp IS NULL
+    OR (created_at, id) > ($4::timestamp, $5::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT $6
 `
 
-func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByUser, userID)
+type ListChirpsParams struct {
+	AuthorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirps(ctx context.Context, arg ListChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirps,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.ExcludeID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +648,13 @@ func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chir
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
 		); err != nil {
 			return nil, err
 		}
@@ -138,14 +669,289 @@ func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chir
 	return items, nil
 }
 
-const getChirpsByUserDesc = `-- name: GetChirpsByUserDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+const listChirpsByHashtag = `-- name: ListChirpsByHashtag :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.rechirp_of, chirps.quote_of, chirps.moderation_status, chirps.deleted_at, chirps.publish_at FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = $1
+AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+AND (chirps.moderation_status = 'visible' OR chirps.user_id = $2)
+AND (
+    $3::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < ($3::timestamp, $4::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $5
+`
+
+type ListChirpsByHashtagParams struct {
+	Tag             string
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirpsByHashtag(ctx context.Context, arg ListChirpsByHashtagParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByHashtag,
+		arg.Tag,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsDesc = `-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND ($1::uuid IS NULL OR user_id = $1)
+AND (moderation_status = 'visible' OR user_id = $2)
+AND ($3::uuid IS NULL OR id <> $3)
+AND (
+    $4::timestamp IS NULL
+    OR (created_at, id) < ($4::timestamp, $5::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $6
+`
+
+type ListChirpsDescParams struct {
+	AuthorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsDesc,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.ExcludeID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsMentioningUser = `-- name: ListChirpsMentioningUser :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $1
+)
+AND moderation_status = 'visible'
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListChirpsMentioningUserParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListChirpsMentioningUser(ctx context.Context, arg ListChirpsMentioningUserParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsMentioningUser,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedChirps = `-- name: ListDeletedChirps :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
 WHERE user_id = $1
-ORDER BY created_at DESC
+AND deleted_at > $2
+AND moderation_status <> 'hidden'
+AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) < ($3::timestamp, $4::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListDeletedChirpsParams struct {
+	UserID          uuid.UUID
+	DeletedAfter    sql.NullTime
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListDeletedChirps(ctx context.Context, arg ListDeletedChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedChirps,
+		arg.UserID,
+		arg.DeletedAfter,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledChirps = `-- name: ListScheduledChirps :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at FROM chirps
+WHERE user_id = $1 AND publish_at IS NOT NULL AND deleted_at IS NULL
+AND (
+    $2::timestamp IS NULL
+    OR (publish_at, id) > ($2::timestamp, $3::uuid)
+)
+ORDER BY publish_at ASC, id ASC
+LIMIT $4
 `
 
-func (q *Queries) GetChirpsByUserDesc(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByUserDesc, userID)
+type ListScheduledChirpsParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) ListScheduledChirps(ctx context.Context, arg ListScheduledChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledChirps,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +965,13 @@ func (q *Queries) GetChirpsByUserDesc(ctx context.Context, userID uuid.UUID) ([]
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
 		); err != nil {
 			return nil, err
 		}
@@ -173,13 +986,26 @@ func (q *Queries) GetChirpsByUserDesc(ctx context.Context, userID uuid.UUID) ([]
 	return items, nil
 }
 
-const getChirpsDesc = `-- name: GetChirpsDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at DESC
+const publishDueChirps = `-- name: PublishDueChirps :many
+UPDATE chirps
+SET created_at = publish_at, updated_at = NOW(), publish_at = NULL
+WHERE id IN (
+    SELECT c.id FROM chirps c
+    WHERE c.publish_at <= $1 AND c.deleted_at IS NULL
+    ORDER BY c.publish_at ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at
 `
 
-func (q *Queries) GetChirpsDesc(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsDesc)
+type PublishDueChirpsParams struct {
+	PublishAt sql.NullTime
+	Limit     int32
+}
+
+func (q *Queries) PublishDueChirps(ctx context.Context, arg PublishDueChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, publishDueChirps, arg.PublishAt, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +1019,114 @@ func (q *Queries) GetChirpsDesc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.ModerationStatus,
+			&i.DeletedAt,
+			&i.PublishAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :exec
+UPDATE chirps SET deleted_at = NULL
+WHERE (id = $1 OR rechirp_of = $1)
+AND deleted_at = (SELECT c.deleted_at FROM chirps c WHERE c.id = $1)
+`
+
+func (q *Queries) RestoreChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, restoreChirp, id)
+	return err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.rechirp_of, chirps.quote_of, chirps.moderation_status, chirps.deleted_at, chirps.publish_at,
+    ts_rank_cd(chirps.search_vector, query)::real AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(replace(replace(chirps.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+        query,
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20'
+    )::text AS snippet
+FROM chirps, to_tsquery('english', $1::text) AS query
+WHERE chirps.search_vector @@ query
+AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+AND ($2::uuid IS NULL OR chirps.user_id = $2)
+AND (chirps.moderation_status = 'visible' OR chirps.user_id = $3)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $4 OFFSET $5
+`
+
+type SearchChirpsParams struct {
+	Query      string
+	AuthorID   uuid.NullUUID
+	ViewerID   uuid.NullUUID
+	PageLimit  int32
+	PageOffset int32
+}
+
+type SearchChirpsRow struct {
+	Chirp   Chirp
+	Rank    float32
+	Snippet string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.ModerationStatus,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.PublishAt,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -206,3 +1140,50 @@ func (q *Queries) GetChirpsDesc(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const setChirpModerationStatus = `-- name: SetChirpModerationStatus :exec
+UPDATE chirps SET moderation_status = $2
+WHERE id = $1
+`
+
+type SetChirpModerationStatusParams struct {
+	ID               uuid.UUID
+	ModerationStatus string
+}
+
+func (q *Queries) SetChirpModerationStatus(ctx context.Context, arg SetChirpModerationStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setChirpModerationStatus, arg.ID, arg.ModerationStatus)
+	return err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps SET body = $2, moderation_status = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to, rechirp_of, quote_of, moderation_status, deleted_at, publish_at
+`
+
+type UpdateChirpBodyParams struct {
+	ID               uuid.UUID
+	Body             string
+	ModerationStatus string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body, arg.ModerationStatus)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.ModerationStatus,
+		&i.DeletedAt,
+		&i.PublishAt,
+	)
+	return i, err
+}
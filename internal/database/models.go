@@ -11,12 +11,135 @@ import (
 	"github.com/google/uuid"
 )
 
+type Attachment struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UserID       uuid.UUID
+	ChirpID      uuid.NullUUID
+	Position     int32
+	ContentType  string
+	SizeBytes    int32
+	Width        int32
+	Height       int32
+	BlobKey      string
+	ThumbnailKey string
+}
+
+type BannedWord struct {
+	Term      string
+	Action    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uuid.NullUUID
+}
+
+type Bookmark struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Body             string
+	UserID           uuid.UUID
+	SearchVector     interface{}
+	InReplyTo        uuid.NullUUID
+	RechirpOf        uuid.NullUUID
+	QuoteOf          uuid.NullUUID
+	ModerationStatus string
+	DeletedAt        sql.NullTime
+	PublishAt        sql.NullTime
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpMention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+	CreatedAt   time.Time
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+}
+
+type Draft struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	UserID    uuid.UUID
 	Body      string
+	InReplyTo uuid.NullUUID
+	QuoteOf   uuid.NullUUID
+}
+
+type EmailVerification struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
 	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Tag       string
+}
+
+type Like struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type PasswordReset struct {
+	TokenHash string
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type Poll struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ChirpID   uuid.UUID
+	ClosesAt  time.Time
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	PollID   uuid.UUID
+	Position int32
+	Label    string
+}
+
+type PollVote struct {
+	PollID    uuid.UUID
+	OptionID  uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,11 +151,48 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
-type User struct {
+type Report struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword sql.NullString
-	IsChirpyRed    sql.NullBool
+	ChirpID        uuid.NullUUID
+	ReporterID     uuid.NullUUID
+	ChirpAuthorID  uuid.UUID
+	ChirpBody      string
+	Reason         string
+	Details        string
+	Status         string
+	Resolution     sql.NullString
+	ResolutionNote string
+	ResolvedBy     uuid.NullUUID
+	ResolvedAt     sql.NullTime
+}
+
+type User struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Email           string
+	HashedPassword  sql.NullString
+	IsChirpyRed     sql.NullBool
+	Handle          sql.NullString
+	IsAdmin         bool
+	SuspendedUntil  sql.NullTime
+	PinnedChirpID   uuid.NullUUID
+	DisplayName     string
+	Bio             string
+	Location        string
+	AvatarID        uuid.NullUUID
+	EmailVerifiedAt sql.NullTime
+	DeleteAfter     sql.NullTime
+}
+
+type Warning struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	ReportID  uuid.NullUUID
+	ChirpID   uuid.NullUUID
+	ChirpBody string
+	Reason    string
+	Note      string
 }
@@ -10,27 +10,43 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const cancelUserDeletion = `-- name: CancelUserDeletion :execrows
+UPDATE users SET delete_after = NULL, updated_at = NOW()
+WHERE id = $1 AND delete_after IS NOT NULL
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelUserDeletion, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, is_admin, suspended_until, pinned_chirp_id, display_name, bio, location, avatar_id, email_verified_at, delete_after
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword sql.NullString
+	Handle         sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Handle)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -39,10 +55,33 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.IsAdmin,
+		&i.SuspendedUntil,
+		&i.PinnedChirpID,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarID,
+		&i.EmailVerifiedAt,
+		&i.DeleteAfter,
 	)
 	return i, err
 }
 
+const deleteUsers = `-- name: DeleteUsers :execrows
+DELETE FROM users
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeleteUsers(ctx context.Context, userIds []uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUsers, pq.Array(userIds))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getToken = `-- name: GetToken :one
 SELECT token, created_at, updated_at, user_id, expires_at, revoked_at FROM refresh_tokens
 WHERE token = $1 AND (expires_at > NOW()) AND (revoked_at IS NULL)
@@ -63,8 +102,8 @@ func (q *Queries) GetToken(ctx context.Context, token string) (RefreshToken, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
-WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, is_admin, suspended_until, pinned_chirp_id, display_name, bio, location, avatar_id, email_verified_at, delete_after FROM users
+WHERE LOWER(email) = LOWER($1)
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -77,24 +116,224 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.IsAdmin,
+		&i.SuspendedUntil,
+		&i.PinnedChirpID,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarID,
+		&i.EmailVerifiedAt,
+		&i.DeleteAfter,
+	)
+	return i, err
+}
+
+const getUserByHandle = `-- name: GetUserByHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, is_admin, suspended_until, pinned_chirp_id, display_name, bio, location, avatar_id, email_verified_at, delete_after FROM users
+WHERE LOWER(handle) = LOWER($1)
+`
+
+func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.IsAdmin,
+		&i.SuspendedUntil,
+		&i.PinnedChirpID,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarID,
+		&i.EmailVerifiedAt,
+		&i.DeleteAfter,
 	)
 	return i, err
 }
 
-const updateUser = `-- name: UpdateUser :one
-UPDATE users SET email = $2, hashed_password = $3, updated_at = NOW()
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, is_admin, suspended_until, pinned_chirp_id, display_name, bio, location, avatar_id, email_verified_at, delete_after FROM users
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
 `
 
-type UpdateUserParams struct {
-	ID             uuid.UUID
-	Email          string
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.IsAdmin,
+		&i.SuspendedUntil,
+		&i.PinnedChirpID,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarID,
+		&i.EmailVerifiedAt,
+		&i.DeleteAfter,
+	)
+	return i, err
+}
+
+const getUserProfileCounts = `-- name: GetUserProfileCounts :one
+SELECT
+    (SELECT COUNT(*) FROM follows WHERE followee_id = $1) AS follower_count,
+    (SELECT COUNT(*) FROM follows WHERE follower_id = $1) AS following_count,
+    (
+        SELECT COUNT(*) FROM chirps
+        WHERE user_id = $1
+        AND deleted_at IS NULL AND publish_at IS NULL
+        AND moderation_status = 'visible'
+    ) AS chirp_count
+`
+
+type GetUserProfileCountsRow struct {
+	FollowerCount  int64
+	FollowingCount int64
+	ChirpCount     int64
+}
+
+func (q *Queries) GetUserProfileCounts(ctx context.Context, userID uuid.UUID) (GetUserProfileCountsRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserProfileCounts, userID)
+	var i GetUserProfileCountsRow
+	err := row.Scan(
+		&i.FollowerCount,
+		&i.FollowingCount,
+		&i.ChirpCount,
+	)
+	return i, err
+}
+
+const getUsersByHandles = `-- name: GetUsersByHandles :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, is_admin, suspended_until, pinned_chirp_id, display_name, bio, location, avatar_id, email_verified_at, delete_after FROM users
+WHERE LOWER(handle) = ANY($1::text[])
+`
+
+func (q *Queries) GetUsersByHandles(ctx context.Context, handles []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByHandles, pq.Array(handles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Handle,
+			&i.IsAdmin,
+			&i.SuspendedUntil,
+			&i.PinnedChirpID,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Location,
+			&i.AvatarID,
+			&i.EmailVerifiedAt,
+			&i.DeleteAfter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUsersDueForDeletion = `-- name: LockUsersDueForDeletion :many
+SELECT id FROM users
+WHERE delete_after <= $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) LockUsersDueForDeletion(ctx context.Context, deleteAfter sql.NullTime) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, lockUsersDueForDeletion, deleteAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const patchUser = `-- name: PatchUser :one
+UPDATE users
+SET
+    email = COALESCE($1, email),
+    email_verified_at = CASE
+        WHEN $1::text IS NOT NULL AND $1 <> email THEN NULL
+        ELSE email_verified_at
+    END,
+    hashed_password = COALESCE($2, hashed_password),
+    handle = COALESCE($3, handle),
+    display_name = COALESCE($4, display_name),
+    bio = COALESCE($5, bio),
+    location = COALESCE($6, location),
+    avatar_id = CASE WHEN $7::boolean THEN $8 ELSE avatar_id END,
+    updated_at = NOW()
+WHERE id = $9
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, is_admin, suspended_until, pinned_chirp_id, display_name, bio, location, avatar_id, email_verified_at, delete_after
+`
+
+type PatchUserParams struct {
+	Email          sql.NullString
 	HashedPassword sql.NullString
+	Handle         sql.NullString
+	DisplayName    sql.NullString
+	Bio            sql.NullString
+	Location       sql.NullString
+	SetAvatar      bool
+	AvatarID       uuid.NullUUID
+	ID             uuid.UUID
 }
 
-func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.ID, arg.Email, arg.HashedPassword)
+func (q *Queries) PatchUser(ctx context.Context, arg PatchUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, patchUser,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Location,
+		arg.SetAvatar,
+		arg.AvatarID,
+		arg.ID,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -103,10 +342,86 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.IsAdmin,
+		&i.SuspendedUntil,
+		&i.PinnedChirpID,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarID,
+		&i.EmailVerifiedAt,
+		&i.DeleteAfter,
 	)
 	return i, err
 }
 
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :execrows
+UPDATE users SET delete_after = $2, updated_at = NOW()
+WHERE id = $1 AND delete_after IS NULL
+`
+
+type ScheduleUserDeletionParams struct {
+	ID          uuid.UUID
+	DeleteAfter sql.NullTime
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, scheduleUserDeletion, arg.ID, arg.DeleteAfter)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setEmailVerified = `-- name: SetEmailVerified :execrows
+UPDATE users SET email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND email = $2
+`
+
+type SetEmailVerifiedParams struct {
+	ID    uuid.UUID
+	Email string
+}
+
+func (q *Queries) SetEmailVerified(ctx context.Context, arg SetEmailVerifiedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setEmailVerified, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setPinnedChirp = `-- name: SetPinnedChirp :exec
+UPDATE users SET pinned_chirp_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPinnedChirpParams struct {
+	ID            uuid.UUID
+	PinnedChirpID uuid.NullUUID
+}
+
+func (q *Queries) SetPinnedChirp(ctx context.Context, arg SetPinnedChirpParams) error {
+	_, err := q.db.ExecContext(ctx, setPinnedChirp, arg.ID, arg.PinnedChirpID)
+	return err
+}
+
+const suspendUser = `-- name: SuspendUser :exec
+UPDATE users SET suspended_until = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SuspendUserParams struct {
+	ID             uuid.UUID
+	SuspendedUntil sql.NullTime
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) error {
+	_, err := q.db.ExecContext(ctx, suspendUser, arg.ID, arg.SuspendedUntil)
+	return err
+}
+
 const updateUserRed = `-- name: UpdateUserRed :exec
 UPDATE users SET is_chirpy_red = true
 WHERE id = $1
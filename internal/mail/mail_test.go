@@ -0,0 +1,170 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		msg     Message
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Headers and body",
+			msg:  Message{To: "sean@example.com", Subject: "Hello", Body: "line one\nline two"},
+			want: []string{
+				"From: Chirpy <no-reply@chirpy.test>\r\n",
+				"To: sean@example.com\r\n",
+				"Subject: Hello\r\n",
+				"Date: Tue, 01 Oct 2024 12:00:00 +0000\r\n",
+				"\r\n\r\nline one\r\nline two",
+			},
+		},
+		{
+			name:    "Line break in recipient",
+			msg:     Message{To: "sean@example.com\r\nBcc: eve@example.com", Subject: "Hello"},
+			wantErr: true,
+		},
+		{
+			name:    "Line break in subject",
+			msg:     Message{To: "sean@example.com", Subject: "Hello\nBcc: eve@example.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := format("Chirpy <no-reply@chirpy.test>", tt.msg, date)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("format() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("format() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := NewFileMailer(dir, "no-reply@chirpy.test")
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+
+	for _, subject := range []string{"First", "Second"} {
+		err = mailer.Send(context.Background(), Message{To: "sean@example.com", Subject: subject, Body: "hi"})
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	raw, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "Subject: First\r\n") {
+		t.Errorf("first file = %q, want the first message", raw)
+	}
+}
+
+func TestNewSMTPMailer(t *testing.T) {
+	tests := []struct {
+		name             string
+		addr             string
+		from             string
+		wantEnvelopeFrom string
+		wantErr          bool
+	}{
+		{
+			name:             "Display name is kept out of the envelope",
+			addr:             "smtp.example.com:587",
+			from:             "Chirpy <no-reply@example.com>",
+			wantEnvelopeFrom: "no-reply@example.com",
+		},
+		{
+			name:             "Bare address",
+			addr:             "smtp.example.com:587",
+			from:             "no-reply@example.com",
+			wantEnvelopeFrom: "no-reply@example.com",
+		},
+		{
+			name:    "Invalid sender",
+			addr:    "smtp.example.com:587",
+			from:    "Chirpy",
+			wantErr: true,
+		},
+		{
+			name:    "Missing port",
+			addr:    "smtp.example.com",
+			from:    "no-reply@example.com",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSMTPMailer(tt.addr, "", "", tt.from)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSMTPMailer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.envelopeFrom != tt.wantEnvelopeFrom {
+				t.Errorf("NewSMTPMailer() envelopeFrom = %q, want %q", got.envelopeFrom, tt.wantEnvelopeFrom)
+			}
+		})
+	}
+}
+
+func TestSMTPMailerSendRespectsContext(t *testing.T) {
+	// A listener that accepts but never speaks SMTP stands in for a stuck
+	// relay.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	mailer, err := NewSMTPMailer(listener.Addr().String(), "", "", "no-reply@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = mailer.Send(ctx, Message{To: "sean@example.com", Subject: "Hello", Body: "hi"})
+	if err == nil {
+		t.Fatal("Send() error = nil, want an error from the stuck relay")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Send() took %s, want it to stop at the context deadline", elapsed)
+	}
+}
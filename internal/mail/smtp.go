@@ -0,0 +1,110 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer sends messages through an SMTP relay. The relay must support
+// STARTTLS when credentials are given, since net/smtp refuses to send
+// them in the clear to anything but localhost.
+type SMTPMailer struct {
+	addr string
+	host string
+	// from is the From header as configured, display name included.
+	// envelopeFrom is the bare address used for MAIL FROM.
+	from         string
+	envelopeFrom string
+	auth         smtp.Auth
+}
+
+// NewSMTPMailer returns a mailer for the relay at addr (host:port). from
+// may include a display name, as in "Chirpy <no-reply@example.com>".
+// Username may be empty for relays that don't authenticate.
+func NewSMTPMailer(addr, username, password, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	m := &SMTPMailer{
+		addr:         addr,
+		host:         host,
+		from:         from,
+		envelopeFrom: sender.Address,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+// Send delivers msg the way smtp.SendMail does, but gives up when ctx is
+// done instead of waiting on a slow relay indefinitely.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := format(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Closing the connection unblocks whatever exchange is in progress.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: m.host})
+		if err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		err = client.Auth(m.auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(m.envelopeFrom)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(msg.To)
+	if err != nil {
+		return err
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = data.Write(raw)
+	if err != nil {
+		return err
+	}
+	err = data.Close()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,226 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	MaxUploadSize      = 5 << 20
+	maxImageDimension  = 8000
+	maxAnimationPixels = 100_000_000
+	thumbnailDimension = 320
+	jpegQuality        = 90
+)
+
+var (
+	ErrTooLarge        = errors.New("Image is too large")
+	ErrUnsupportedType = errors.New("Only JPEG, PNG and GIF images are supported")
+)
+
+// Image is an upload that has been validated and re-encoded.
+type Image struct {
+	ContentType string
+	Extension   string
+	Data        []byte
+	Width       int
+	Height      int
+
+	ThumbnailContentType string
+	ThumbnailExtension   string
+	Thumbnail            []byte
+}
+
+// ProcessImage validates an uploaded image by sniffing its content rather
+// than trusting the client's MIME type, then re-encodes it. Re-encoding
+// drops EXIF and every other metadata block, so location data and camera
+// details never reach the blob store.
+func ProcessImage(data []byte) (Image, error) {
+	if len(data) > MaxUploadSize {
+		return Image{}, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return Image{}, ErrUnsupportedType
+	}
+
+	// Check the dimensions before decoding so a tiny file that claims to
+	// be enormous can't exhaust memory.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, err
+	}
+	if config.Width > maxImageDimension || config.Height > maxImageDimension {
+		return Image{}, ErrTooLarge
+	}
+	// Every frame of a GIF is decoded at the full screen size, so a small
+	// file with many frames is limited the same way.
+	if contentType == "image/gif" {
+		frames, err := gifFrameCount(data)
+		if err != nil {
+			return Image{}, err
+		}
+		if frames*config.Width*config.Height > maxAnimationPixels {
+			return Image{}, ErrTooLarge
+		}
+	}
+
+	processed := Image{
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+	}
+
+	var decoded image.Image
+	buf := bytes.Buffer{}
+	switch contentType {
+	case "image/jpeg":
+		decoded, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, err
+		}
+		err = jpeg.Encode(&buf, decoded, &jpeg.Options{Quality: jpegQuality})
+		processed.Extension = ".jpg"
+	case "image/png":
+		decoded, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, err
+		}
+		err = png.Encode(&buf, decoded)
+		processed.Extension = ".png"
+	case "image/gif":
+		var animation *gif.GIF
+		animation, err = gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, err
+		}
+		decoded = animation.Image[0]
+		err = gif.EncodeAll(&buf, animation)
+		processed.Extension = ".gif"
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	processed.Data = buf.Bytes()
+
+	thumbnail := Thumbnail(decoded, thumbnailDimension)
+	thumbBuf := bytes.Buffer{}
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&thumbBuf, thumbnail, &jpeg.Options{Quality: jpegQuality})
+		processed.ThumbnailContentType = "image/jpeg"
+		processed.ThumbnailExtension = ".jpg"
+	} else {
+		err = png.Encode(&thumbBuf, thumbnail)
+		processed.ThumbnailContentType = "image/png"
+		processed.ThumbnailExtension = ".png"
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	processed.Thumbnail = thumbBuf.Bytes()
+
+	return processed, nil
+}
+
+var errMalformedGIF = errors.New("gif: malformed data")
+
+// gifFrameCount counts the frames in a GIF by walking its block structure,
+// without decompressing any image data.
+func gifFrameCount(data []byte) (int, error) {
+	// Header and logical screen descriptor.
+	if len(data) < 13 {
+		return 0, errMalformedGIF
+	}
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << ((flags & 0x07) + 1)
+	}
+
+	frames := 0
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // extension
+			pos += 2
+		case 0x2C: // image descriptor
+			if pos+10 > len(data) {
+				return 0, errMalformedGIF
+			}
+			frames++
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << ((flags & 0x07) + 1)
+			}
+			// LZW minimum code size.
+			pos++
+		case 0x3B: // trailer
+			return frames, nil
+		default:
+			return 0, errMalformedGIF
+		}
+
+		// Both extensions and image data end in a run of sub-blocks.
+		for {
+			if pos >= len(data) {
+				return 0, errMalformedGIF
+			}
+			size := int(data[pos])
+			pos += 1 + size
+			if size == 0 {
+				break
+			}
+		}
+	}
+	return 0, errMalformedGIF
+}
+
+// Thumbnail scales img down to fit within a maxDimension square, keeping
+// its aspect ratio, by averaging the source pixels behind each output
+// pixel. Images that already fit are copied unscaled.
+func Thumbnail(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > maxDimension || srcH > maxDimension {
+		if srcW >= srcH {
+			dstW, dstH = maxDimension, max(1, srcH*maxDimension/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*maxDimension/srcH), maxDimension
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
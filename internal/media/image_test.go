@@ -0,0 +1,146 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodeGIF builds an animation that repeats one blank frame. Sharing the
+// frame keeps large test animations cheap to build.
+func encodeGIF(t *testing.T, width, height, frames int) []byte {
+	t.Helper()
+	frame := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black, color.White})
+	animation := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 10)
+	}
+	buf := bytes.Buffer{}
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		wantErr       bool
+		wantThumbSize image.Point
+	}{
+		{
+			name:          "Large PNG is thumbnailed",
+			data:          encodePNG(t, 640, 480),
+			wantThumbSize: image.Point{X: 320, Y: 240},
+		},
+		{
+			name:          "Tall PNG keeps aspect ratio",
+			data:          encodePNG(t, 100, 1000),
+			wantThumbSize: image.Point{X: 32, Y: 320},
+		},
+		{
+			name:          "Small PNG is not upscaled",
+			data:          encodePNG(t, 10, 20),
+			wantThumbSize: image.Point{X: 10, Y: 20},
+		},
+		{
+			name:    "Text is rejected",
+			data:    []byte("definitely not an image"),
+			wantErr: true,
+		},
+		{
+			name:    "Too large",
+			data:    make([]byte, MaxUploadSize+1),
+			wantErr: true,
+		},
+		{
+			name:    "GIF with too many frames",
+			data:    encodeGIF(t, 4000, 4000, 7),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessImage(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.ContentType != "image/png" {
+				t.Errorf("ProcessImage() ContentType = %v, want image/png", got.ContentType)
+			}
+			thumb, _, err := image.DecodeConfig(bytes.NewReader(got.Thumbnail))
+			if err != nil {
+				t.Fatalf("thumbnail doesn't decode: %v", err)
+			}
+			if thumb.Width != tt.wantThumbSize.X || thumb.Height != tt.wantThumbSize.Y {
+				t.Errorf("thumbnail size = %dx%d, want %dx%d", thumb.Width, thumb.Height, tt.wantThumbSize.X, tt.wantThumbSize.Y)
+			}
+		})
+	}
+}
+
+func TestGIFFrameCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Single frame",
+			data: encodeGIF(t, 10, 10, 1),
+			want: 1,
+		},
+		{
+			name: "Animation",
+			data: encodeGIF(t, 10, 10, 5),
+			want: 5,
+		},
+		{
+			name:    "Truncated",
+			data:    encodeGIF(t, 10, 10, 2)[:30],
+			wantErr: true,
+		},
+		{
+			name:    "Header only",
+			data:    []byte("GIF89a"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gifFrameCount(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gifFrameCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("gifFrameCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
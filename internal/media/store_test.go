@@ -0,0 +1,74 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreOpen(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := store.Put(ctx, "blob.png", strings.NewReader("image data")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		want     string
+		notExist bool
+		wantErr  bool
+	}{
+		{
+			name: "Stored blob",
+			key:  "blob.png",
+			want: "image data",
+		},
+		{
+			name:     "Missing blob",
+			key:      "missing.png",
+			notExist: true,
+			wantErr:  true,
+		},
+		{
+			name:    "Key with a path separator",
+			key:     "../blob.png",
+			wantErr: true,
+		},
+		{
+			name:    "Parent directory",
+			key:     "..",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob, err := store.Open(ctx, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.notExist && !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Open() error = %v, want os.ErrNotExist", err)
+			}
+			if err != nil {
+				return
+			}
+			defer blob.Close()
+			data, err := io.ReadAll(blob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Open() read %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
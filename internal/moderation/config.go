@@ -0,0 +1,82 @@
+package moderation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Config describes a filter chain. It is read from a JSON file at startup
+// so rules can change without a code change.
+type Config struct {
+	Words          []WordConfig    `json:"words"`
+	Patterns       []PatternConfig `json:"patterns"`
+	BlockedDomains []string        `json:"blocked_domains"`
+	// BlockedDomainAction defaults to Reject when left out.
+	BlockedDomainAction *Action `json:"blocked_domain_action"`
+}
+
+type WordConfig struct {
+	Word   string `json:"word"`
+	Action Action `json:"action"`
+}
+
+type PatternConfig struct {
+	Pattern string `json:"pattern"`
+	Action  Action `json:"action"`
+	Reason  string `json:"reason"`
+}
+
+func LoadConfig(path string) (Config, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	config := Config{}
+	err = json.Unmarshal(dat, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Chain builds the filters described by the config: words first, then
+// patterns, then links.
+func (c Config) Chain() (Chain, error) {
+	chain := Chain{}
+
+	if len(c.Words) > 0 {
+		terms := make(map[string]Action, len(c.Words))
+		for _, word := range c.Words {
+			terms[word.Word] = word.Action
+		}
+		chain = append(chain, NewWordList(terms))
+	}
+
+	if len(c.Patterns) > 0 {
+		rules := make([]RegexRule, 0, len(c.Patterns))
+		for _, pattern := range c.Patterns {
+			compiled, err := regexp.Compile(pattern.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("compiling pattern %q: %w", pattern.Pattern, err)
+			}
+			reason := pattern.Reason
+			if reason == "" {
+				reason = pattern.Pattern
+			}
+			rules = append(rules, RegexRule{Pattern: compiled, Action: pattern.Action, Reason: reason})
+		}
+		chain = append(chain, NewRegexFilter(rules))
+	}
+
+	if len(c.BlockedDomains) > 0 {
+		action := Reject
+		if c.BlockedDomainAction != nil {
+			action = *c.BlockedDomainAction
+		}
+		chain = append(chain, NewLinkBlocklist(c.BlockedDomains, action))
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,64 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// linkPattern finds things that look like links, with or without a scheme.
+// The host is the first submatch.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://)?((?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,})\b(?:[/?#:][^\s]*)?`)
+
+// LinkBlocklist flags links to blocked domains. A blocked domain also
+// covers all of its subdomains.
+type LinkBlocklist struct {
+	domains map[string]bool
+	action  Action
+}
+
+func NewLinkBlocklist(domains []string, action Action) *LinkBlocklist {
+	blocked := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			blocked[domain] = true
+		}
+	}
+	return &LinkBlocklist{domains: blocked, action: action}
+}
+
+func (l *LinkBlocklist) Check(body string) Verdict {
+	verdict := Verdict{Action: Allow, Body: body}
+	if len(l.domains) == 0 || l.action == Allow {
+		return verdict
+	}
+
+	redacted := linkPattern.ReplaceAllStringFunc(body, func(link string) string {
+		host := strings.ToLower(linkPattern.FindStringSubmatch(link)[1])
+		if !l.blocked(host) {
+			return link
+		}
+		verdict.Action = l.action
+		verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("link to %s: %s", host, l.action))
+		if l.action == Redact {
+			return mask
+		}
+		return link
+	})
+	verdict.Body = redacted
+	return verdict
+}
+
+func (l *LinkBlocklist) blocked(host string) bool {
+	for {
+		if l.domains[host] {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return false
+		}
+		host = parent
+	}
+}
@@ -0,0 +1,91 @@
+package moderation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action is what a filter wants done with a chirp. Actions are ordered by
+// severity so a chain can keep the strictest one it sees.
+type Action int
+
+const (
+	Allow Action = iota
+	Redact
+	Hold
+	Reject
+)
+
+const mask = "****"
+
+var actionNames = map[Action]string{
+	Allow:  "allow",
+	Redact: "redact",
+	Hold:   "hold",
+	Reject: "reject",
+}
+
+func (a Action) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// ParseAction accepts the names returned by String, case-insensitively.
+func ParseAction(s string) (Action, error) {
+	for action, name := range actionNames {
+		if strings.EqualFold(s, name) {
+			return action, nil
+		}
+	}
+	return Allow, fmt.Errorf("unknown moderation action %q", s)
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Action) UnmarshalText(text []byte) error {
+	parsed, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Verdict is the outcome of checking a body. Body is the text to store,
+// with any redactions applied, and Reasons explains every rule that fired.
+type Verdict struct {
+	Action  Action
+	Body    string
+	Reasons []string
+}
+
+// Filter inspects a chirp body. A filter that doesn't redact anything must
+// return the body it was given unchanged.
+type Filter interface {
+	Check(body string) Verdict
+}
+
+// Chain runs filters in order, feeding each one the body as redacted by the
+// filters before it. The strictest action wins and a rejection stops the
+// chain early.
+type Chain []Filter
+
+func (c Chain) Check(body string) Verdict {
+	verdict := Verdict{Action: Allow, Body: body}
+	for _, filter := range c {
+		result := filter.Check(verdict.Body)
+		verdict.Body = result.Body
+		verdict.Reasons = append(verdict.Reasons, result.Reasons...)
+		if result.Action > verdict.Action {
+			verdict.Action = result.Action
+		}
+		if verdict.Action == Reject {
+			break
+		}
+	}
+	return verdict
+}
@@ -0,0 +1,260 @@
+package moderation
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestWordList(t *testing.T) {
+	list := NewWordList(map[string]Action{
+		"kerfuffle": Redact,
+		"fornax":    Redact,
+		"spamword":  Hold,
+		"slur":      Reject,
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantAction Action
+		wantBody   string
+	}{
+		{
+			name:       "Hyphenated word",
+			body:       "This is a kerfuffle-free chirp? No.",
+			wantAction: Redact,
+			wantBody:   "This is a ****-free chirp? No.",
+		},
+		{
+			name:       "Nothing banned",
+			body:       "hello world",
+			wantAction: Allow,
+			wantBody:   "hello world",
+		},
+		{
+			name:       "Trailing punctuation",
+			body:       "Fornax!",
+			wantAction: Redact,
+			wantBody:   "****!",
+		},
+		{
+			name:       "Accents and case",
+			body:       "what a FÖRNAX",
+			wantAction: Redact,
+			wantBody:   "what a ****",
+		},
+		{
+			name:       "Full-width letters",
+			body:       "ｆｏｒｎａｘ here",
+			wantAction: Redact,
+			wantBody:   "**** here",
+		},
+		{
+			name:       "Zero-width space inside word",
+			body:       "forn\u200bax",
+			wantAction: Redact,
+			wantBody:   "****",
+		},
+		{
+			name:       "Substring is not a match",
+			body:       "fornaxes are fine",
+			wantAction: Allow,
+			wantBody:   "fornaxes are fine",
+		},
+		{
+			name:       "Hold keeps the body",
+			body:       "buy spamword now",
+			wantAction: Hold,
+			wantBody:   "buy spamword now",
+		},
+		{
+			name:       "Strictest action wins",
+			body:       "fornax slur",
+			wantAction: Reject,
+			wantBody:   "**** slur",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := list.Check(tt.body)
+			if got.Action != tt.wantAction {
+				t.Errorf("Check().Action = %v, want %v", got.Action, tt.wantAction)
+			}
+			if got.Body != tt.wantBody {
+				t.Errorf("Check().Body = %q, want %q", got.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestLinkBlocklist(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     Action
+		body       string
+		wantAction Action
+		wantBody   string
+	}{
+		{
+			name:       "Unlisted domain",
+			action:     Reject,
+			body:       "see https://example.com/page",
+			wantAction: Allow,
+			wantBody:   "see https://example.com/page",
+		},
+		{
+			name:       "Blocked domain",
+			action:     Reject,
+			body:       "see https://bad.test/page",
+			wantAction: Reject,
+			wantBody:   "see https://bad.test/page",
+		},
+		{
+			name:       "Subdomain without scheme",
+			action:     Redact,
+			body:       "go to www.BAD.test/x now",
+			wantAction: Redact,
+			wantBody:   "go to **** now",
+		},
+		{
+			name:       "Lookalike domain",
+			action:     Reject,
+			body:       "notbad.test is fine",
+			wantAction: Allow,
+			wantBody:   "notbad.test is fine",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewLinkBlocklist([]string{"bad.test"}, tt.action).Check(tt.body)
+			if got.Action != tt.wantAction {
+				t.Errorf("Check().Action = %v, want %v", got.Action, tt.wantAction)
+			}
+			if got.Body != tt.wantBody {
+				t.Errorf("Check().Body = %q, want %q", got.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain := Chain{
+		NewWordList(map[string]Action{"fornax": Redact}),
+		NewRegexFilter([]RegexRule{
+			{Pattern: regexp.MustCompile(`\d{3}-\d{4}`), Action: Redact, Reason: "phone number"},
+			{Pattern: regexp.MustCompile(`(?i)free money`), Action: Hold, Reason: "spam"},
+		}),
+		NewLinkBlocklist([]string{"bad.test"}, Reject),
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantAction Action
+		wantBody   string
+	}{
+		{
+			name:       "Redactions from several filters combine",
+			body:       "fornax call 555-1234",
+			wantAction: Redact,
+			wantBody:   "**** call ****",
+		},
+		{
+			name:       "Hold outranks redact",
+			body:       "FREE MONEY fornax",
+			wantAction: Hold,
+			wantBody:   "FREE MONEY ****",
+		},
+		{
+			name:       "Reject outranks everything",
+			body:       "free money at bad.test",
+			wantAction: Reject,
+			wantBody:   "free money at bad.test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chain.Check(tt.body)
+			if got.Action != tt.wantAction {
+				t.Errorf("Check().Action = %v, want %v", got.Action, tt.wantAction)
+			}
+			if got.Body != tt.wantBody {
+				t.Errorf("Check().Body = %q, want %q", got.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Action
+		wantErr bool
+	}{
+		{input: "allow", want: Allow},
+		{input: "Redact", want: Redact},
+		{input: "HOLD", want: Hold},
+		{input: "reject", want: Reject},
+		{input: "delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAction(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAction() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseAction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTerm(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "Fornax", want: "fornax"},
+		{input: "  Crème ", want: "creme"},
+		{input: "two words", wantErr: true},
+		{input: "fornax!", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeTerm(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NormalizeTerm() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeTerm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachedWordList(t *testing.T) {
+	cache := &CachedWordList{}
+	if got := cache.Check("fornax"); got.Action != Allow {
+		t.Errorf("empty cache Check().Action = %v, want %v", got.Action, Allow)
+	}
+
+	cache.Store(map[string]Action{"fornax": Reject})
+	if got := cache.Check("fornax"); got.Action != Reject {
+		t.Errorf("Check().Action = %v, want %v", got.Action, Reject)
+	}
+
+	cache.Store(map[string]Action{})
+	if got := cache.Check("fornax"); got.Action != Allow {
+		t.Errorf("Check().Action after Store = %v, want %v", got.Action, Allow)
+	}
+}
@@ -0,0 +1,41 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RegexRule applies Action wherever Pattern matches. Reason is reported in
+// the verdict and should say what the rule is for.
+type RegexRule struct {
+	Pattern *regexp.Regexp
+	Action  Action
+	Reason  string
+}
+
+// RegexFilter checks a body against each rule in turn. Redacting rules
+// replace every match with a mask.
+type RegexFilter struct {
+	rules []RegexRule
+}
+
+func NewRegexFilter(rules []RegexRule) *RegexFilter {
+	return &RegexFilter{rules: rules}
+}
+
+func (f *RegexFilter) Check(body string) Verdict {
+	verdict := Verdict{Action: Allow, Body: body}
+	for _, rule := range f.rules {
+		if rule.Action == Allow || !rule.Pattern.MatchString(verdict.Body) {
+			continue
+		}
+		verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("pattern %q: %s", rule.Reason, rule.Action))
+		if rule.Action > verdict.Action {
+			verdict.Action = rule.Action
+		}
+		if rule.Action == Redact {
+			verdict.Body = rule.Pattern.ReplaceAllLiteralString(verdict.Body, mask)
+		}
+	}
+	return verdict
+}
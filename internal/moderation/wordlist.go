@@ -0,0 +1,140 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// WordList matches whole words against a set of terms, each with its own
+// action. Words are split on anything that isn't a letter, digit or
+// combining mark, so punctuation around a word doesn't hide it, and both
+// sides are compared after normalization so "FÖRNAX" matches "fornax".
+type WordList struct {
+	terms map[string]Action
+}
+
+func NewWordList(terms map[string]Action) *WordList {
+	normalized := make(map[string]Action, len(terms))
+	for term, action := range terms {
+		key := normalizeWord(term)
+		if key == "" {
+			continue
+		}
+		if existing, ok := normalized[key]; !ok || action > existing {
+			normalized[key] = action
+		}
+	}
+	return &WordList{terms: normalized}
+}
+
+func (l *WordList) Check(body string) Verdict {
+	verdict := Verdict{Action: Allow, Body: body}
+	if len(l.terms) == 0 {
+		return verdict
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, span := range wordSpans(body) {
+		word := body[span[0]:span[1]]
+		action, ok := l.terms[normalizeWord(word)]
+		if !ok || action == Allow {
+			continue
+		}
+		verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("word %q: %s", word, action))
+		if action > verdict.Action {
+			verdict.Action = action
+		}
+		if action == Redact {
+			out.WriteString(body[last:span[0]])
+			out.WriteString(mask)
+			last = span[1]
+		}
+	}
+	if last > 0 {
+		out.WriteString(body[last:])
+		verdict.Body = out.String()
+	}
+	return verdict
+}
+
+// CachedWordList is a WordList that can be replaced while chirps are being
+// checked, for terms that are loaded from storage and change at runtime.
+// It matches nothing until the first Store.
+type CachedWordList struct {
+	current atomic.Pointer[WordList]
+}
+
+func (c *CachedWordList) Store(terms map[string]Action) {
+	c.current.Store(NewWordList(terms))
+}
+
+func (c *CachedWordList) Check(body string) Verdict {
+	list := c.current.Load()
+	if list == nil {
+		return Verdict{Action: Allow, Body: body}
+	}
+	return list.Check(body)
+}
+
+// NormalizeTerm returns the form a WordList compares term in. It fails
+// unless term is exactly one word, since WordList only matches whole words.
+func NormalizeTerm(term string) (string, error) {
+	term = strings.TrimSpace(term)
+	spans := wordSpans(term)
+	if len(spans) != 1 || spans[0] != [2]int{0, len(term)} {
+		return "", errors.New("term must be a single word")
+	}
+	normalized := normalizeWord(term)
+	if normalized == "" {
+		return "", errors.New("term must be a single word")
+	}
+	return normalized, nil
+}
+
+// wordSpans returns the byte ranges of the words in s. Invisible format
+// characters such as zero-width spaces are treated as part of a word so
+// they can't be used to split one.
+func wordSpans(s string) [][2]int {
+	spans := [][2]int{}
+	start := -1
+	for i, r := range s {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(s)})
+	}
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r)
+}
+
+// normalizeWord folds compatibility forms (full-width letters, ligatures),
+// strips accents and invisible characters, and lowercases.
+func normalizeWord(word string) string {
+	decomposed := norm.NFKD.String(word)
+	var b strings.Builder
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
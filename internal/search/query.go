@@ -0,0 +1,98 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ParseQuery turns a user supplied search string into to_tsquery syntax.
+// Bare words are ANDed together, "quoted text" becomes a phrase, a trailing
+// * makes a prefix match and a leading - excludes a word.
+func ParseQuery(q string) (string, error) {
+	terms := []string{}
+	for _, token := range tokenize(q) {
+		term := token.toTSQuery()
+		if term == "" {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return "", errors.New("Search query has no searchable words")
+	}
+	return strings.Join(terms, " & "), nil
+}
+
+type token struct {
+	text   string
+	phrase bool
+	prefix bool
+	negate bool
+}
+
+func tokenize(q string) []token {
+	tokens := []token{}
+	for q != "" {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+
+		t := token{}
+		if strings.HasPrefix(q, "-") {
+			t.negate = true
+			q = q[1:]
+		}
+
+		if strings.HasPrefix(q, `"`) {
+			end := strings.Index(q[1:], `"`)
+			if end == -1 {
+				t.text, q = q[1:], ""
+			} else {
+				t.text, q = q[1:end+1], q[end+2:]
+			}
+			t.phrase = true
+			tokens = append(tokens, t)
+			continue
+		}
+
+		end := strings.IndexFunc(q, unicode.IsSpace)
+		if end == -1 {
+			end = len(q)
+		}
+		t.text, q = q[:end], q[end:]
+		if strings.HasSuffix(t.text, "*") {
+			t.prefix = true
+			t.text = strings.TrimRight(t.text, "*")
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+// toTSQuery renders a token, splitting on anything that isn't a letter or
+// digit so that user input can never inject tsquery operators.
+func (t token) toTSQuery() string {
+	words := strings.FieldsFunc(t.text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	if t.prefix {
+		words[len(words)-1] += ":*"
+	}
+
+	term := strings.Join(words, " <-> ")
+	if len(words) > 1 && (t.negate || !t.phrase) {
+		term = "(" + term + ")"
+	}
+	if t.negate {
+		term = "!" + term
+	}
+	return term
+}
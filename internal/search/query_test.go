@@ -0,0 +1,66 @@
+package search
+
+import "testing"
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Single word",
+			query: "chirpy",
+			want:  "chirpy",
+		},
+		{
+			name:  "Words are ANDed",
+			query: "hello  World",
+			want:  "hello & world",
+		},
+		{
+			name:  "Phrase",
+			query: `"good morning" world`,
+			want:  "good <-> morning & world",
+		},
+		{
+			name:  "Unterminated phrase",
+			query: `"good morning`,
+			want:  "good <-> morning",
+		},
+		{
+			name:  "Prefix",
+			query: "chir*",
+			want:  "chir:*",
+		},
+		{
+			name:  "Negation",
+			query: "bird -cat",
+			want:  "bird & !cat",
+		},
+		{
+			name:  "Operators are stripped",
+			query: "a|b & c:*",
+			want:  "(a <-> b) & c:*",
+		},
+		{
+			name:    "Nothing searchable",
+			query:   ` "" - !! `,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,26 +1,47 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	"github.com/seantesterman/chirpy/internal/database"
+	"github.com/seantesterman/chirpy/internal/mail"
+	"github.com/seantesterman/chirpy/internal/media"
+	"github.com/seantesterman/chirpy/internal/moderation"
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	db             *database.Queries
-	platform       string
-	secret         string
-	polkaKey       string
+	fileserverHits   atomic.Int32
+	db               *database.Queries
+	dbConn           *sql.DB
+	platform         string
+	secret           string
+	polkaKey         string
+	maxChirpLength   int
+	chirpRetention   time.Duration
+	blobStore        media.BlobStore
+	bannedWords      *moderation.CachedWordList
+	chirpFilter      moderation.Filter
+	mailer           mail.Mailer
+	unverifiedPolicy unverifiedPolicy
 }
 
+// defaultMaxChirpLength is in user-perceived characters, see chirptext.Length.
+const defaultMaxChirpLength = 140
+
+// defaultChirpRetention is how long deleted chirps stay restorable before
+// they are purged.
+const defaultChirpRetention = 30 * 24 * time.Hour
+
 func main() {
 	const filepathRoot = "."
 	const port = "8080"
@@ -46,6 +67,73 @@ func main() {
 		log.Fatal("POLKA_KEY must be set")
 	}
 
+	maxChirpLength := defaultMaxChirpLength
+	if raw := os.Getenv("MAX_CHIRP_LENGTH"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			log.Fatal("MAX_CHIRP_LENGTH must be a positive integer")
+		}
+		maxChirpLength = parsed
+	}
+
+	chirpRetention := defaultChirpRetention
+	if raw := os.Getenv("CHIRP_RETENTION"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			log.Fatal("CHIRP_RETENTION must be a positive duration such as 720h")
+		}
+		chirpRetention = parsed
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+	blobStore, err := media.NewLocalStore(mediaDir, "/media")
+	if err != nil {
+		log.Fatalf("Error opening media directory: %s", err)
+	}
+
+	policy := unverifiedAllow
+	if raw := os.Getenv("UNVERIFIED_ACCOUNTS"); raw != "" {
+		policy, err = parseUnverifiedPolicy(raw)
+		if err != nil {
+			log.Fatal("UNVERIFIED_ACCOUNTS must be allow or read_only")
+		}
+	}
+
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "Chirpy <no-reply@localhost>"
+	}
+	var mailer mail.Mailer
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		mailer, err = mail.NewSMTPMailer(smtpAddr, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), mailFrom)
+		if err != nil {
+			log.Fatalf("Error configuring SMTP: %s", err)
+		}
+	} else if mailDir := os.Getenv("MAIL_DIR"); mailDir != "" {
+		mailer, err = mail.NewFileMailer(mailDir, mailFrom)
+		if err != nil {
+			log.Fatalf("Error opening mail directory: %s", err)
+		}
+	} else {
+		mailer = mail.NewLogMailer(mailFrom)
+	}
+
+	moderationConfig := moderation.Config{}
+	if path := os.Getenv("MODERATION_CONFIG"); path != "" {
+		moderationConfig, err = moderation.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("Error loading moderation config: %s", err)
+		}
+	}
+	configFilters, err := moderationConfig.Chain()
+	if err != nil {
+		log.Fatalf("Error building moderation filters: %s", err)
+	}
+	bannedWords := &moderation.CachedWordList{}
+
 	dbConn, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatalf("Error opening database: %s", err)
@@ -53,34 +141,105 @@ func main() {
 	dbQueries := database.New(dbConn)
 
 	apiCfg := apiConfig{
-		fileserverHits: atomic.Int32{},
-		db:             dbQueries,
-		platform:       platform,
-		secret:         secret,
-		polkaKey:       polkaKey,
+		fileserverHits:   atomic.Int32{},
+		db:               dbQueries,
+		dbConn:           dbConn,
+		platform:         platform,
+		secret:           secret,
+		polkaKey:         polkaKey,
+		maxChirpLength:   maxChirpLength,
+		chirpRetention:   chirpRetention,
+		blobStore:        blobStore,
+		bannedWords:      bannedWords,
+		chirpFilter:      moderation.Chain{bannedWords, configFilters},
+		mailer:           mailer,
+		unverifiedPolicy: policy,
+	}
+
+	err = apiCfg.reloadBannedWords(context.Background())
+	if err != nil {
+		log.Fatalf("Error loading banned words: %s", err)
 	}
 
+	go apiCfg.runChirpPurge(context.Background(), chirpPurgeInterval)
+	go apiCfg.runChirpScheduler(context.Background(), chirpSchedulerInterval)
+	go apiCfg.runAccountPurge(context.Background(), accountPurgeInterval)
+
 	r := mux.NewRouter()
 	r.Handle("/app/", http.StripPrefix("/app", apiCfg.middlewareMetricsInc(http.FileServer(http.Dir(filepathRoot)))))
+	r.HandleFunc("/media/{key}", apiCfg.handlerMediaGet).Methods("GET")
 	r.HandleFunc("/api/healthz", handlerReadiness).Methods("GET")
 
 	r.HandleFunc("/admin/metrics", apiCfg.handlerMetrics).Methods("GET")
 	r.HandleFunc("/admin/reset", apiCfg.handlerReset).Methods("POST")
+	r.HandleFunc("/admin/banned-words", apiCfg.handlerBannedWordsGet).Methods("GET")
+	r.HandleFunc("/admin/banned-words", apiCfg.handlerBannedWordsCreate).Methods("POST")
+	r.HandleFunc("/admin/banned-words/{term}", apiCfg.handlerBannedWordsDelete).Methods("DELETE")
+	r.HandleFunc("/admin/reports", apiCfg.handlerReportsGet).Methods("GET")
+	r.HandleFunc("/admin/reports/{reportID}/resolve", apiCfg.handlerReportResolve).Methods("POST")
 
 	r.HandleFunc("/api/users", apiCfg.handlerUsersCreate).Methods("POST")
 	r.HandleFunc("/api/users", apiCfg.handlerUsersUpdate).Methods("PUT")
+	r.HandleFunc("/api/users/verify", apiCfg.handlerUsersVerify).Methods("POST")
+	r.HandleFunc("/api/users/verify/resend", apiCfg.handlerUsersVerifyResend).Methods("POST")
+	r.HandleFunc("/api/users/me", apiCfg.handlerUsersMeUpdate).Methods("PATCH")
+	r.HandleFunc("/api/users/me", apiCfg.handlerUsersMeDelete).Methods("DELETE")
+	r.HandleFunc("/api/users/me/cancel-deletion", apiCfg.handlerUsersMeCancelDeletion).Methods("POST")
+	r.HandleFunc("/api/users/me/mentions", apiCfg.handlerMentionsGet).Methods("GET")
+	r.HandleFunc("/api/users/me/trash", apiCfg.handlerTrashGet).Methods("GET")
+	r.HandleFunc("/api/users/me/warnings", apiCfg.handlerWarningsGet).Methods("GET")
+	r.HandleFunc("/api/users/me/scheduled", apiCfg.handlerScheduledGet).Methods("GET")
+	r.HandleFunc("/api/users/me/scheduled/{chirpID}", apiCfg.handlerScheduledDelete).Methods("DELETE")
+	r.HandleFunc("/api/users/me/bookmarks", apiCfg.handlerBookmarksGet).Methods("GET")
+	r.HandleFunc("/api/users/me/pin", apiCfg.handlerPinCreate).Methods("POST")
+	r.HandleFunc("/api/users/me/pin", apiCfg.handlerPinDelete).Methods("DELETE")
+	r.HandleFunc("/api/users/{handle}", apiCfg.handlerUserProfile).Methods("GET")
+	r.HandleFunc("/api/users/{userID}/follow", apiCfg.handlerFollowCreate).Methods("POST")
+	r.HandleFunc("/api/users/{userID}/follow", apiCfg.handlerFollowDelete).Methods("DELETE")
+	r.HandleFunc("/api/users/{userID}/followers", apiCfg.handlerFollowersGet).Methods("GET")
+	r.HandleFunc("/api/users/{userID}/following", apiCfg.handlerFollowingGet).Methods("GET")
+
+	r.HandleFunc("/api/timeline", apiCfg.handlerTimeline).Methods("GET")
+
+	r.HandleFunc("/api/attachments", apiCfg.handlerAttachmentsCreate).Methods("POST")
+
+	r.HandleFunc("/api/drafts", apiCfg.handlerDraftsCreate).Methods("POST")
+	r.HandleFunc("/api/drafts", apiCfg.handlerDraftsGet).Methods("GET")
+	r.HandleFunc("/api/drafts/{draftID}", apiCfg.handlerDraftsID).Methods("GET")
+	r.HandleFunc("/api/drafts/{draftID}", apiCfg.handlerDraftsUpdate).Methods("PUT")
+	r.HandleFunc("/api/drafts/{draftID}", apiCfg.handlerDraftsDelete).Methods("DELETE")
+	r.HandleFunc("/api/drafts/{draftID}/publish", apiCfg.handlerDraftsPublish).Methods("POST")
 
 	r.HandleFunc("/api/chirps", apiCfg.handlerChirpsCreate).Methods("POST")
 	r.HandleFunc("/api/chirps", apiCfg.handlerChirpsGet).Methods("GET")
+	r.HandleFunc("/api/chirps/search", apiCfg.handlerChirpsSearch).Methods("GET")
+
+	r.HandleFunc("/api/hashtags/trending", apiCfg.handlerHashtagsTrending).Methods("GET")
+	r.HandleFunc("/api/hashtags/{tag}/chirps", apiCfg.handlerHashtagChirpsGet).Methods("GET")
 
 	r.HandleFunc("/api/login", apiCfg.handlerLogin).Methods("POST")
 
+	r.HandleFunc("/api/password-reset/request", apiCfg.handlerPasswordResetRequest).Methods("POST")
+	r.HandleFunc("/api/password-reset/confirm", apiCfg.handlerPasswordResetConfirm).Methods("POST")
+
 	r.HandleFunc("/api/refresh", apiCfg.handlerRefreshToken).Methods("POST")
 
 	r.HandleFunc("/api/revoke", apiCfg.handlerRevokeToken).Methods("POST")
 
 	r.HandleFunc("/api/chirps/{chirpID}", apiCfg.handlerChirpsID).Methods("GET")
+	r.HandleFunc("/api/chirps/{chirpID}", apiCfg.handlerChirpsUpdate).Methods("PUT", "PATCH")
 	r.HandleFunc("/api/chirps/{chirpID}", apiCfg.handlerChirpsDelete).Methods("DELETE")
+	r.HandleFunc("/api/chirps/{chirpID}/restore", apiCfg.handlerChirpsRestore).Methods("POST")
+	r.HandleFunc("/api/chirps/{chirpID}/revisions", apiCfg.handlerChirpRevisionsGet).Methods("GET")
+	r.HandleFunc("/api/chirps/{chirpID}/thread", apiCfg.handlerChirpsThread).Methods("GET")
+	r.HandleFunc("/api/chirps/{chirpID}/like", apiCfg.handlerLikeCreate).Methods("POST")
+	r.HandleFunc("/api/chirps/{chirpID}/like", apiCfg.handlerLikeDelete).Methods("DELETE")
+	r.HandleFunc("/api/chirps/{chirpID}/rechirp", apiCfg.handlerRechirpCreate).Methods("POST")
+	r.HandleFunc("/api/chirps/{chirpID}/rechirp", apiCfg.handlerRechirpDelete).Methods("DELETE")
+	r.HandleFunc("/api/chirps/{chirpID}/poll/vote", apiCfg.handlerPollVote).Methods("POST")
+	r.HandleFunc("/api/chirps/{chirpID}/bookmark", apiCfg.handlerBookmarkCreate).Methods("POST")
+	r.HandleFunc("/api/chirps/{chirpID}/bookmark", apiCfg.handlerBookmarkDelete).Methods("DELETE")
+	r.HandleFunc("/api/chirps/{chirpID}/report", apiCfg.handlerReportCreate).Methods("POST")
 
 	r.HandleFunc("/api/polka/webhooks", apiCfg.handlerPolkaWebhook).Methods("POST")
 
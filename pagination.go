@@ -0,0 +1,124 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+type pageParams struct {
+	Limit  int32
+	Cursor *pageCursor
+}
+
+// parsePageParams reads the limit and cursor query parameters shared by every
+// keyset-paginated listing.
+func parsePageParams(r *http.Request) (pageParams, error) {
+	params := pageParams{Limit: defaultPageLimit}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			return pageParams{}, errors.New("Invalid limit")
+		}
+		if parsed > maxPageLimit {
+			parsed = maxPageLimit
+		}
+		params.Limit = int32(parsed)
+	}
+
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			return pageParams{}, errors.New("Invalid cursor")
+		}
+		params.Cursor = &decoded
+	}
+
+	return params, nil
+}
+
+// fetchLimit asks for one extra row so we know whether another page exists.
+func (p pageParams) fetchLimit() int32 {
+	return p.Limit + 1
+}
+
+func (p pageParams) cursorCreatedAt() sql.NullTime {
+	if p.Cursor == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: p.Cursor.CreatedAt, Valid: true}
+}
+
+func (p pageParams) cursorID() uuid.NullUUID {
+	if p.Cursor == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: p.Cursor.ID, Valid: true}
+}
+
+// paginate trims a result fetched with fetchLimit down to the page size and
+// returns the cursor for the following page, if there is one.
+func paginate[T any](items []T, p pageParams, key func(T) (time.Time, uuid.UUID)) ([]T, string) {
+	if len(items) <= int(p.Limit) {
+		return items, ""
+	}
+	items = items[:p.Limit]
+	createdAt, id := key(items[len(items)-1])
+	return items, encodeCursor(createdAt, id)
+}
+
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	dat, _ := json.Marshal(pageCursor{CreatedAt: createdAt, ID: id})
+	return base64.RawURLEncoding.EncodeToString(dat)
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	dat, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageCursor{}, err
+	}
+	decoded := pageCursor{}
+	err = json.Unmarshal(dat, &decoded)
+	if err != nil {
+		return pageCursor{}, err
+	}
+	if decoded.ID == uuid.Nil || decoded.CreatedAt.IsZero() {
+		return pageCursor{}, errors.New("incomplete cursor")
+	}
+	return decoded, nil
+}
+
+// setNextLink advertises the next page as an RFC 8288 Link header, keeping
+// every other query parameter of the current request.
+func setNextLink(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	setNextLinkParam(w, r, "cursor", nextCursor)
+}
+
+func setNextLinkParam(w http.ResponseWriter, r *http.Request, param, value string) {
+	if value == "" {
+		return
+	}
+	next := *r.URL
+	query := next.Query()
+	query.Set(param, value)
+	next.RawQuery = query.Encode()
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+}
@@ -0,0 +1,50 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (id, created_at, user_id, content_type, size_bytes, width, height, blob_key, thumbnail_key)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING *;
+
+-- name: AttachToChirp :execrows
+UPDATE attachments SET chirp_id = $1, position = $2
+WHERE id = $3 AND user_id = $4 AND chirp_id IS NULL
+AND NOT EXISTS (SELECT 1 FROM users WHERE users.avatar_id = attachments.id);
+
+-- name: GetAttachmentsForChirps :many
+SELECT * FROM attachments
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: GetAttachment :one
+SELECT * FROM attachments
+WHERE id = $1;
+
+-- name: GetUnattachedAttachment :one
+SELECT * FROM attachments
+WHERE id = $1 AND user_id = $2 AND chirp_id IS NULL;
+
+-- name: GetAttachmentsForUsers :many
+SELECT * FROM attachments
+WHERE user_id = ANY(sqlc.arg('user_ids')::uuid[]);
+
+-- name: GetAttachmentByKey :one
+SELECT
+    sqlc.embed(attachments),
+    EXISTS (SELECT 1 FROM users WHERE users.avatar_id = attachments.id) AS is_avatar
+FROM attachments
+WHERE blob_key = sqlc.arg('key') OR thumbnail_key = sqlc.arg('key');
+
+-- name: DeleteUnattachedAttachments :many
+DELETE FROM attachments
+WHERE chirp_id IS NULL
+AND created_at < NOW() - (sqlc.arg('max_age_seconds')::int * INTERVAL '1 second')
+AND NOT EXISTS (SELECT 1 FROM users WHERE users.avatar_id = attachments.id)
+RETURNING *;
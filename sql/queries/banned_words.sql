@@ -0,0 +1,19 @@
+-- name: ListBannedWords :many
+SELECT * FROM banned_words
+ORDER BY term;
+
+-- name: UpsertBannedWord :one
+INSERT INTO banned_words (term, action, created_at, updated_at, created_by)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    $3
+)
+ON CONFLICT (term) DO UPDATE SET action = EXCLUDED.action, updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteBannedWord :execrows
+DELETE FROM banned_words
+WHERE term = $1;
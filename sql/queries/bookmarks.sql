@@ -0,0 +1,30 @@
+-- name: BookmarkChirp :exec
+INSERT INTO bookmarks (user_id, chirp_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: UnbookmarkChirp :exec
+DELETE FROM bookmarks
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: GetBookmarkedChirpIDs :many
+SELECT chirp_id FROM bookmarks
+WHERE user_id = sqlc.arg('user_id') AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: ListBookmarkedChirps :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at
+FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+AND (chirps.moderation_status = 'visible' OR chirps.user_id = sqlc.arg('user_id'))
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (bookmarks.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY bookmarks.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit');
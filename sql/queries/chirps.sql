@@ -1,36 +1,260 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, quote_of, moderation_status, publish_at)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    '',
+    $1,
     $2
 )
+ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL DO NOTHING
 RETURNING *;
 
--- name: GetChirps :many
+-- name: DeleteRechirp :execrows
+DELETE FROM chirps
+WHERE user_id = $1 AND rechirp_of = $2 AND deleted_at IS NULL;
+
+-- name: ListChirps :many
 SELECT * FROM chirps
-ORDER BY created_at ASC;
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND (moderation_status = 'visible' OR user_id = sqlc.narg('viewer_id'))
+AND (sqlc.narg('exclude_id')::uuid IS NULL OR id <> sqlc.narg('exclude_id'))
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('page_limit');
 
--- name: GetChirpsDesc :many
+-- name: ListChirpsDesc :many
 SELECT * FROM chirps
-ORDER BY created_at DESC;
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND (moderation_status = 'visible' OR user_id = sqlc.narg('viewer_id'))
+AND (sqlc.narg('exclude_id')::uuid IS NULL OR id <> sqlc.narg('exclude_id'))
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
 
 -- name: GetChirp :one
 SELECT * FROM chirps
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: DeleteChirp :exec
+UPDATE chirps SET deleted_at = $2
+WHERE (id = $1 OR rechirp_of = $1) AND deleted_at IS NULL;
+
+-- name: GetDeletedChirp :one
+SELECT * FROM chirps
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: RestoreChirp :exec
+UPDATE chirps SET deleted_at = NULL
+WHERE (id = $1 OR rechirp_of = $1)
+AND deleted_at = (SELECT c.deleted_at FROM chirps c WHERE c.id = $1);
+
+-- name: ListDeletedChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+AND deleted_at > sqlc.arg('deleted_after')
+AND moderation_status <> 'hidden'
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetPurgeableAttachments :many
+SELECT attachments.* FROM attachments
+JOIN chirps ON chirps.id = attachments.chirp_id
+WHERE chirps.deleted_at < $1;
+
+-- name: PurgeDeletedChirps :execrows
 DELETE FROM chirps
-WHERE id = $1;
+WHERE deleted_at < $1;
+
+-- name: SearchChirps :many
+SELECT
+    sqlc.embed(chirps),
+    ts_rank_cd(chirps.search_vector, query)::real AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(replace(replace(chirps.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+        query,
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20'
+    )::text AS snippet
+FROM chirps, to_tsquery('english', sqlc.arg('query')::text) AS query
+WHERE chirps.search_vector @@ query
+AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id'))
+AND (chirps.moderation_status = 'visible' OR chirps.user_id = sqlc.narg('viewer_id'))
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
 
--- name: GetChirpsByUser :many
+-- name: GetChirpForUpdate :one
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps SET body = $2, moderation_status = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetReplyCounts :many
+SELECT in_reply_to::uuid AS chirp_id, COUNT(*) AS reply_count
+FROM chirps
+WHERE in_reply_to = ANY(sqlc.arg('chirp_ids')::uuid[]) AND deleted_at IS NULL AND publish_at IS NULL
+AND moderation_status = 'visible'
+GROUP BY in_reply_to;
 
--- name: GetChirpsByUserDesc :many
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT parent.id, parent.in_reply_to, 1::int AS depth
+    FROM chirps child
+    JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE child.id = sqlc.arg('chirp_id')
+    UNION ALL
+    SELECT c.id, c.in_reply_to, ancestors.depth + 1
+    FROM chirps c
+    JOIN ancestors ON c.id = ancestors.in_reply_to
+    WHERE ancestors.depth < sqlc.arg('max_depth')::int
+)
+SELECT chirps.* FROM chirps
+JOIN ancestors ON chirps.id = ancestors.id
+WHERE chirps.deleted_at IS NULL
+ORDER BY ancestors.depth DESC;
+
+-- name: GetChirpReplyTree :many
+WITH RECURSIVE tree AS (
+    SELECT page.id, 1::int AS depth
+    FROM (
+        SELECT c.id FROM chirps c
+        WHERE c.in_reply_to = sqlc.arg('chirp_id')
+        AND c.deleted_at IS NULL AND c.publish_at IS NULL
+        AND (c.moderation_status = 'visible' OR c.user_id = sqlc.narg('viewer_id'))
+        AND (
+            sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (c.created_at, c.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+        )
+        ORDER BY c.created_at ASC, c.id ASC
+        LIMIT sqlc.arg('page_limit')
+    ) page
+    UNION ALL
+    SELECT c.id, tree.depth + 1
+    FROM chirps c
+    JOIN tree ON c.in_reply_to = tree.id
+    WHERE tree.depth < sqlc.arg('max_depth')::int
+    AND c.deleted_at IS NULL AND c.publish_at IS NULL
+    AND (c.moderation_status = 'visible' OR c.user_id = sqlc.narg('viewer_id'))
+)
+SELECT sqlc.embed(chirps), tree.depth
+FROM chirps
+JOIN tree ON chirps.id = tree.id
+ORDER BY tree.depth ASC, chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg('max_replies');
+
+-- name: GetTimeline :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND (
+    user_id = sqlc.arg('user_id')
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id'))
+)
+AND (moderation_status = 'visible' OR user_id = sqlc.arg('user_id'))
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]) AND deleted_at IS NULL;
+
+-- name: GetRechirpCounts :many
+SELECT rechirp_of::uuid AS chirp_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of = ANY(sqlc.arg('chirp_ids')::uuid[]) AND deleted_at IS NULL
+GROUP BY rechirp_of;
+
+-- name: ListChirpsByHashtag :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg('tag')
+AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+AND (chirps.moderation_status = 'visible' OR chirps.user_id = sqlc.narg('viewer_id'))
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListChirpsMentioningUser :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at DESC;
\ No newline at end of file
+WHERE deleted_at IS NULL AND publish_at IS NULL
+AND EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.arg('user_id')
+)
+AND moderation_status = 'visible'
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: SetChirpModerationStatus :exec
+UPDATE chirps SET moderation_status = $2
+WHERE id = $1;
+
+-- name: ListScheduledChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id') AND publish_at IS NOT NULL AND deleted_at IS NULL
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (publish_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY publish_at ASC, id ASC
+LIMIT sqlc.arg('page_limit');
+
+-- name: DeleteScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND publish_at IS NOT NULL;
+
+-- name: PublishDueChirps :many
+UPDATE chirps
+SET created_at = publish_at, updated_at = NOW(), publish_at = NULL
+WHERE id IN (
+    SELECT c.id FROM chirps c
+    WHERE c.publish_at <= $1 AND c.deleted_at IS NULL
+    ORDER BY c.publish_at ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
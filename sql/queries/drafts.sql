@@ -0,0 +1,41 @@
+-- name: CreateDraft :one
+INSERT INTO drafts (id, created_at, updated_at, user_id, body, in_reply_to, quote_of)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT * FROM drafts
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetDraftForUpdate :one
+SELECT * FROM drafts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE;
+
+-- name: ListDrafts :many
+SELECT * FROM drafts
+WHERE user_id = sqlc.arg('user_id')
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (updated_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $3, in_reply_to = $4, quote_of = $5, updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,41 @@
+-- name: UpsertHashtag :one
+INSERT INTO hashtags (id, created_at, tag)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1
+)
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING *;
+
+-- name: AddChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (chirp_id, hashtag_id) DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: GetHashtagsForChirps :many
+SELECT chirp_hashtags.chirp_id, hashtags.tag
+FROM chirp_hashtags
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE chirp_hashtags.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY hashtags.tag ASC;
+
+-- name: GetTrendingHashtags :many
+SELECT hashtags.tag, COUNT(*) AS chirp_count
+FROM chirp_hashtags
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirp_hashtags.created_at > NOW() - (sqlc.arg('window_seconds')::int * INTERVAL '1 second')
+AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+AND chirps.moderation_status = 'visible'
+GROUP BY hashtags.tag
+ORDER BY chirp_count DESC, hashtags.tag ASC
+LIMIT sqlc.arg('page_limit');
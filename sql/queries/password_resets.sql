@@ -0,0 +1,24 @@
+-- name: CreatePasswordReset :exec
+INSERT INTO password_resets (token_hash, created_at, user_id, expires_at)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3
+);
+
+-- name: UsePasswordReset :one
+UPDATE password_resets SET used_at = NOW()
+WHERE token_hash = sqlc.arg('token_hash') AND used_at IS NULL AND expires_at > sqlc.arg('now')
+RETURNING *;
+
+-- name: InvalidatePasswordResets :exec
+UPDATE password_resets SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: HasRecentPasswordReset :one
+SELECT EXISTS (
+    SELECT 1 FROM password_resets
+    WHERE user_id = sqlc.arg('user_id') AND used_at IS NULL
+    AND created_at > NOW() - (sqlc.arg('window_seconds')::int * INTERVAL '1 second')
+);
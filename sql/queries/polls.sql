@@ -0,0 +1,55 @@
+-- name: CreatePoll :one
+INSERT INTO polls (id, created_at, chirp_id, closes_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2
+)
+RETURNING *;
+
+-- name: CreatePollOption :exec
+INSERT INTO poll_options (id, poll_id, position, label)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3
+);
+
+-- name: GetPollByChirpID :one
+SELECT * FROM polls
+WHERE chirp_id = $1;
+
+-- name: CastPollVote :one
+INSERT INTO poll_votes (poll_id, option_id, user_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+ON CONFLICT (poll_id, user_id) DO NOTHING
+RETURNING *;
+
+-- name: GetPollResults :many
+SELECT
+    polls.chirp_id,
+    polls.id AS poll_id,
+    polls.closes_at,
+    poll_options.id AS option_id,
+    poll_options.label,
+    COUNT(poll_votes.user_id) AS vote_count
+FROM polls
+JOIN poll_options ON poll_options.poll_id = polls.id
+LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id
+WHERE polls.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY polls.id, poll_options.id
+ORDER BY polls.chirp_id, poll_options.position;
+
+-- name: GetPollVotesByUser :many
+SELECT polls.chirp_id, poll_votes.option_id
+FROM poll_votes
+JOIN polls ON polls.id = poll_votes.poll_id
+WHERE poll_votes.user_id = sqlc.arg('user_id')
+AND polls.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
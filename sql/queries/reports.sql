@@ -0,0 +1,41 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, chirp_id, reporter_id, chirp_author_id, chirp_body, reason, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT (reporter_id, chirp_id) WHERE status = 'open' DO NOTHING
+RETURNING *;
+
+-- name: ListReports :many
+SELECT * FROM reports
+WHERE status = sqlc.arg('status')
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetReportForUpdate :one
+SELECT * FROM reports
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ResolveReports :many
+UPDATE reports
+SET
+    status = 'closed',
+    resolution = sqlc.arg('resolution'),
+    resolution_note = sqlc.arg('resolution_note'),
+    resolved_by = sqlc.arg('resolved_by'),
+    resolved_at = NOW()
+WHERE status = 'open'
+AND (id = sqlc.arg('id') OR chirp_id = sqlc.narg('chirp_id'))
+RETURNING *;
@@ -1,30 +1,95 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
-WHERE email = $1;
+WHERE LOWER(email) = LOWER($1);
 
 -- name: GetToken :one
 SELECT * FROM refresh_tokens
 WHERE token = $1 AND (expires_at > NOW()) AND (revoked_at IS NULL);
 
--- name: UpdateUser :one
-UPDATE users SET email = $2, hashed_password = $3, updated_at = NOW()
-WHERE id = $1
-RETURNING *;
-
 -- name: UpdateUserRed :exec
 UPDATE users SET is_chirpy_red = true
 WHERE id = $1;
 
 
 
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUsersByHandles :many
+SELECT * FROM users
+WHERE LOWER(handle) = ANY(sqlc.arg('handles')::text[]);
+
+-- name: SuspendUser :exec
+UPDATE users SET suspended_until = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetPinnedChirp :exec
+UPDATE users SET pinned_chirp_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetUserByHandle :one
+SELECT * FROM users
+WHERE LOWER(handle) = LOWER(sqlc.arg('handle'));
+
+-- name: PatchUser :one
+UPDATE users
+SET
+    email = COALESCE(sqlc.narg('email'), email),
+    email_verified_at = CASE
+        WHEN sqlc.narg('email')::text IS NOT NULL AND sqlc.narg('email') <> email THEN NULL
+        ELSE email_verified_at
+    END,
+    hashed_password = COALESCE(sqlc.narg('hashed_password'), hashed_password),
+    handle = COALESCE(sqlc.narg('handle'), handle),
+    display_name = COALESCE(sqlc.narg('display_name'), display_name),
+    bio = COALESCE(sqlc.narg('bio'), bio),
+    location = COALESCE(sqlc.narg('location'), location),
+    avatar_id = CASE WHEN sqlc.arg('set_avatar')::boolean THEN sqlc.narg('avatar_id') ELSE avatar_id END,
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: GetUserProfileCounts :one
+SELECT
+    (SELECT COUNT(*) FROM follows WHERE followee_id = sqlc.arg('user_id')) AS follower_count,
+    (SELECT COUNT(*) FROM follows WHERE follower_id = sqlc.arg('user_id')) AS following_count,
+    (
+        SELECT COUNT(*) FROM chirps
+        WHERE user_id = sqlc.arg('user_id')
+        AND deleted_at IS NULL AND publish_at IS NULL
+        AND moderation_status = 'visible'
+    ) AS chirp_count;
+
+-- name: SetEmailVerified :execrows
+UPDATE users SET email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND email = $2;
+
+-- name: ScheduleUserDeletion :execrows
+UPDATE users SET delete_after = $2, updated_at = NOW()
+WHERE id = $1 AND delete_after IS NULL;
+
+-- name: CancelUserDeletion :execrows
+UPDATE users SET delete_after = NULL, updated_at = NOW()
+WHERE id = $1 AND delete_after IS NOT NULL;
+
+-- name: LockUsersDueForDeletion :many
+SELECT id FROM users
+WHERE delete_after <= $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: DeleteUsers :execrows
+DELETE FROM users
+WHERE id = ANY(sqlc.arg('user_ids')::uuid[]);
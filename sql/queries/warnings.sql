@@ -0,0 +1,23 @@
+-- name: CreateWarning :one
+INSERT INTO warnings (id, created_at, user_id, report_id, chirp_id, chirp_body, reason, note)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: ListWarnings :many
+SELECT * FROM warnings
+WHERE user_id = sqlc.arg('user_id')
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
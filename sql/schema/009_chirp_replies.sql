@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN in_reply_to UUID REFERENCES chirps(id) ON DELETE SET NULL;
+
+CREATE INDEX chirps_in_reply_to_idx ON chirps (in_reply_to, created_at, id);
+
+-- +goose Down
+DROP INDEX chirps_in_reply_to_idx;
+
+ALTER TABLE chirps
+DROP COLUMN in_reply_to;
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN moderation_status TEXT NOT NULL DEFAULT 'visible'
+CHECK (moderation_status IN ('visible', 'held'));
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN moderation_status;
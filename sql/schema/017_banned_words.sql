@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE banned_words (
+    term TEXT PRIMARY KEY,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'reject', 'flag')),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+INSERT INTO banned_words (term, action, created_at, updated_at)
+VALUES
+    ('kerfuffle', 'mask', NOW(), NOW()),
+    ('sharbert', 'mask', NOW(), NOW()),
+    ('fornax', 'mask', NOW(), NOW());
+
+-- +goose Down
+DROP TABLE banned_words;
+
+ALTER TABLE users
+DROP COLUMN is_admin;
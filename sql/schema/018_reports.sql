@@ -0,0 +1,43 @@
+-- +goose Up
+ALTER TABLE chirps
+DROP CONSTRAINT chirps_moderation_status_check,
+ADD CONSTRAINT chirps_moderation_status_check
+CHECK (moderation_status IN ('visible', 'held', 'hidden'));
+
+ALTER TABLE users
+ADD COLUMN suspended_until TIMESTAMP;
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    reporter_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    chirp_author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_body TEXT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other', 'automated')),
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    resolution TEXT CHECK (resolution IN ('dismiss', 'hide', 'delete', 'warn', 'suspend')),
+    resolution_note TEXT NOT NULL DEFAULT '',
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX reports_reporter_id_chirp_id_open_key ON reports (reporter_id, chirp_id)
+WHERE status = 'open';
+
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at, id);
+CREATE INDEX reports_chirp_id_idx ON reports (chirp_id);
+
+-- +goose Down
+DROP TABLE reports;
+
+ALTER TABLE users
+DROP COLUMN suspended_until;
+
+UPDATE chirps SET moderation_status = 'held' WHERE moderation_status = 'hidden';
+
+ALTER TABLE chirps
+DROP CONSTRAINT chirps_moderation_status_check,
+ADD CONSTRAINT chirps_moderation_status_check
+CHECK (moderation_status IN ('visible', 'held'));
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX chirps_deleted_at_idx ON chirps (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+DROP INDEX chirps_user_id_rechirp_of_key;
+CREATE UNIQUE INDEX chirps_user_id_rechirp_of_key ON chirps (user_id, rechirp_of)
+WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DELETE FROM chirps WHERE deleted_at IS NOT NULL;
+
+DROP INDEX chirps_user_id_rechirp_of_key;
+CREATE UNIQUE INDEX chirps_user_id_rechirp_of_key ON chirps (user_id, rechirp_of)
+WHERE rechirp_of IS NOT NULL;
+
+DROP INDEX chirps_deleted_at_idx;
+
+ALTER TABLE chirps
+DROP COLUMN deleted_at;
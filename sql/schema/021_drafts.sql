@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE drafts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    in_reply_to UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    quote_of UUID REFERENCES chirps(id) ON DELETE SET NULL
+);
+
+CREATE INDEX drafts_user_id_updated_at_idx ON drafts (user_id, updated_at, id);
+
+-- +goose Down
+DROP TABLE drafts;
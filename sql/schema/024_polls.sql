@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE polls (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL UNIQUE REFERENCES chirps(id) ON DELETE CASCADE,
+    closes_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE poll_options (
+    id UUID PRIMARY KEY,
+    poll_id UUID NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    CONSTRAINT poll_options_poll_id_position_key UNIQUE (poll_id, position),
+    CONSTRAINT poll_options_poll_id_id_key UNIQUE (poll_id, id)
+);
+
+-- The composite foreign key stops a vote naming an option from another poll.
+CREATE TABLE poll_votes (
+    poll_id UUID NOT NULL,
+    option_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT poll_votes_poll_id_user_id_key UNIQUE (poll_id, user_id),
+    FOREIGN KEY (poll_id, option_id) REFERENCES poll_options(poll_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX poll_votes_option_id_idx ON poll_votes (option_id);
+
+-- +goose Down
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Accounts created before verification existed are trusted as they are.
+UPDATE users SET email_verified_at = created_at;
+
+CREATE TABLE email_verifications (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX email_verifications_user_id_idx ON email_verifications (user_id);
+
+-- +goose Down
+DROP TABLE email_verifications;
+
+ALTER TABLE users
+DROP COLUMN email_verified_at;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE warnings (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    report_id UUID REFERENCES reports(id) ON DELETE SET NULL,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    chirp_body TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX warnings_user_id_created_at_idx ON warnings (user_id, created_at, id);
+
+-- +goose Down
+DROP TABLE warnings;
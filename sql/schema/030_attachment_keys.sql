@@ -0,0 +1,7 @@
+-- +goose Up
+CREATE UNIQUE INDEX attachments_blob_key_key ON attachments (blob_key);
+CREATE UNIQUE INDEX attachments_thumbnail_key_key ON attachments (thumbnail_key);
+
+-- +goose Down
+DROP INDEX attachments_thumbnail_key_key;
+DROP INDEX attachments_blob_key_key;
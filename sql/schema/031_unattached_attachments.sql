@@ -0,0 +1,6 @@
+-- +goose Up
+CREATE INDEX attachments_unattached_created_at_idx ON attachments (created_at)
+WHERE chirp_id IS NULL;
+
+-- +goose Down
+DROP INDEX attachments_unattached_created_at_idx;
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/seantesterman/chirpy/internal/auth"
+)
+
+// viewerID returns the user behind a request's bearer token, or uuid.Nil
+// when the request is anonymous or the token doesn't validate. Public
+// endpoints use it to personalise responses without requiring a login.
+func (cfg *apiConfig) viewerID(r *http.Request) uuid.UUID {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	userID, err := auth.ValidateJWT(bearerToken, cfg.secret)
+	if err != nil {
+		return uuid.Nil
+	}
+	return userID
+}